@@ -3,12 +3,54 @@ package main
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 )
 
+// configName is the name of this experiment's config file.
+const configName = "02-spin-midi.json"
+
+// DefaultConfigPath returns the config path within the user's config folder
+// (e.g. $XDG_CONFIG_HOME/friday/02-spin-midi.json), or configName in the current
+// working directory if the user's config folder can't be determined.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return configName
+	}
+	return filepath.Join(dir, "friday", configName)
+}
+
 type Config struct {
-	MidiDevice      string `json:"midi_device"`
-	Knob1Chan       int    `json:"knob1_chan"`
-	Knob1Controller int    `json:"knob1_controller"`
+	MidiDevice      string  `json:"midi_device"`
+	Knob1Chan       int     `json:"knob1_chan"`
+	Knob1Controller int     `json:"knob1_controller"`
+	Theme           string  `json:"theme,omitempty"`  // name of the theme to start with
+	Themes          []Theme `json:"themes,omitempty"` // added to the built-in themes
+}
+
+// AllThemes returns the built-in themes, followed by the config's themes.
+// A config theme with the same name as a built-in one replaces it.
+func (c Config) AllThemes() []Theme {
+	themes := BuiltinThemes()
+	for _, t := range c.Themes {
+		i := findTheme(themes, t.Name)
+		if i < 0 {
+			themes = append(themes, t)
+		} else {
+			themes[i] = t
+		}
+	}
+	return themes
+}
+
+// findTheme returns the index of the theme with the given name, or -1.
+func findTheme(themes []Theme, name string) int {
+	for i, t := range themes {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
 }
 
 func LoadConfig(path string) (Config, error) {
@@ -24,6 +66,9 @@ func LoadConfig(path string) (Config, error) {
 }
 
 func SaveConfig(path string, config Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
 	fp, err := os.Create(path)
 	if err != nil {
 		return err
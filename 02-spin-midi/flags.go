@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+)
+
+type Flags struct {
+	ConfigPath   string
+	Device       string
+	Reconfigure  bool
+	ListDevices  bool
+	WindowWidth  int
+	WindowHeight int
+	Fullscreen   bool
+}
+
+func ParseFlags() Flags {
+	var f Flags
+	flag.StringVar(&f.ConfigPath, "config", DefaultConfigPath(), "path to the config file")
+	flag.StringVar(&f.Device, "device", "", "MIDI device to use (overrides the config file)")
+	flag.BoolVar(&f.Reconfigure, "reconfigure", false, "run the setup wizard, even if a config file already exists")
+	flag.BoolVar(&f.ListDevices, "list-devices", false, "list available MIDI devices and exit")
+	flag.IntVar(&f.WindowWidth, "width", screenWidth, "window width")
+	flag.IntVar(&f.WindowHeight, "height", screenHeight, "window height")
+	flag.BoolVar(&f.Fullscreen, "fullscreen", false, "start in fullscreen mode")
+	flag.Parse()
+	return f
+}
@@ -1,12 +1,12 @@
 package main
 
 import (
-	"image/color"
 	"math"
 	"math/rand"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 var shapeSrc []Point = []Point{
@@ -29,6 +29,10 @@ type GameScene struct {
 	rot     int // [0,127]
 	shape   []Point
 	rotGoal int // [0,127]
+
+	themes     []Theme // cycled through with the T key
+	themeIndex int
+	tint       float32 // [0,1] how far the dial is tinted toward the theme's success color
 }
 
 func NewGameScene(midiMgr *MidiMgr) *GameScene {
@@ -36,9 +40,28 @@ func NewGameScene(midiMgr *MidiMgr) *GameScene {
 		midiMgr: midiMgr,
 		center:  Point{X: screenWidth / 2, Y: screenHeight / 2},
 		rotGoal: rand.Intn(128),
+		themes:  BuiltinThemes(),
+	}
+}
+
+// SetThemes replaces the themes that the T key cycles through, and switches
+// to the one with the given name (or the first, if it isn't found).
+func (g *GameScene) SetThemes(themes []Theme, name string) {
+	g.themes = themes
+	g.themeIndex = 0
+	if i := findTheme(themes, name); i >= 0 {
+		g.themeIndex = i
 	}
 }
 
+// Theme returns the colors to draw with.
+func (g *GameScene) Theme() Theme {
+	if g.themeIndex < len(g.themes) {
+		return g.themes[g.themeIndex]
+	}
+	return DefaultTheme()
+}
+
 const (
 	sliceCount = 64 // number of keypresses to complete one full rotation
 	twoPi      = math.Pi * 2
@@ -47,6 +70,8 @@ const (
 	maxRot     = twoPi - minRot           // anything over this rounds up to 2π (aka 0)
 
 	scale = 3
+
+	tintTicks = 10 // updates it takes to tint the dial when it clicks, or untint it when it's turned away
 )
 
 func (g *GameScene) Update(mgr *SceneMgr) error {
@@ -55,6 +80,17 @@ func (g *GameScene) Update(mgr *SceneMgr) error {
 	prevRot := g.rot
 	g.rot = g.midiMgr.Knob1()
 
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) && len(g.themes) > 0 {
+		g.themeIndex = (g.themeIndex + 1) % len(g.themes)
+	}
+
+	// fade the tint in and out, rather than snapping it on the click
+	if g.rot == g.rotGoal {
+		g.tint = min32(g.tint+1.0/tintTicks, 1)
+	} else {
+		g.tint = max32(g.tint-1.0/tintTicks, 0)
+	}
+
 	// if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
 	// 	g.rot -= sliceRad
 	// 	if g.rot < -minRot {
@@ -85,17 +121,13 @@ func (g *GameScene) Update(mgr *SceneMgr) error {
 	return nil
 }
 
-var (
-	colorBG = color.RGBA{0x56, 0x55, 0x54, 0xff}
-	colorFG = color.RGBA{0xf6, 0xf1, 0x93, 0xee}
-)
-
 func (g *GameScene) Draw(mgr *SceneMgr, screen *ebiten.Image) {
-	screen.Fill(colorBG)
-	drawShape(screen, g.shape, 1, colorFG)
+	th := g.Theme()
+	screen.Fill(th.Background)
+	drawShape(screen, g.shape, 1, LerpColor(th.Foreground, th.Success, g.tint))
 
 	// msg := fmt.Sprintf("TPS: %0.2f\nRot: %.3f", ebiten.ActualTPS(), g.rot)
-	msg := "Spin the dial with left and right arrows"
+	msg := "Spin the dial with left and right arrows\nPress T to change theme"
 	if g.rot == g.rotGoal {
 		msg += "\n\nCLICK!"
 	}
@@ -1,20 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"image"
 	"image/color"
 	"log"
 	"os"
-	"strconv"
-	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 
 	"gitlab.com/gomidi/midi/v2"
 	_ "gitlab.com/gomidi/midi/v2/drivers/rtmididrv" // autoregisters driver
+
+	"github.com/danbrakeley/friday/02-spin-midi/prompt"
 )
 
 const (
@@ -81,18 +80,37 @@ type Point struct {
 func main() {
 	defer midi.CloseDriver()
 
-	cfg, err := LoadConfig("config.json")
-	if os.IsNotExist(err) || len(cfg.MidiDevice) == 0 {
-		cfg, err = CreateConfig()
+	flags := ParseFlags()
+
+	if flags.ListDevices {
+		printInPorts(midi.GetInPorts())
+		return
+	}
+
+	cfg, err := LoadConfig(flags.ConfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		// fail rather than run the setup wizard, which would overwrite a file that may have been edited by hand
+		log.Fatalf("LoadConfig(%s): %v", flags.ConfigPath, err)
+	}
+	if flags.Reconfigure || os.IsNotExist(err) || len(cfg.MidiDevice) == 0 {
+		newCfg, err := CreateConfig(prompt.New(os.Stdin, os.Stdout))
 		if err != nil {
 			log.Fatal(err)
 		}
-		err = SaveConfig("config.json", cfg)
+		// the wizard only sets up the MIDI device, so keep any themes
+		newCfg.Theme, newCfg.Themes = cfg.Theme, cfg.Themes
+		cfg = newCfg
+		err = SaveConfig(flags.ConfigPath, cfg)
 		if err != nil {
 			log.Fatal(err)
 		}
+		fmt.Printf("Saved %s\n", flags.ConfigPath)
 	} else {
-		fmt.Println("Loaded config.json")
+		fmt.Printf("Loaded %s\n", flags.ConfigPath)
+	}
+
+	if len(flags.Device) > 0 {
+		cfg.MidiDevice = flags.Device
 	}
 
 	mgr := NewSceneMgr()
@@ -103,27 +121,39 @@ func main() {
 	defer midiMgr.Close()
 	// mgr.AddScene(SceneSplash, NewSplashScene())
 	// mgr.SwitchScene(SceneSplash)
-	mgr.AddScene(SceneGame, NewGameScene(midiMgr))
+	scene := NewGameScene(midiMgr)
+	themes := cfg.AllThemes()
+	if len(cfg.Theme) > 0 && findTheme(themes, cfg.Theme) < 0 {
+		log.Fatalf("%s: theme \"%s\" not found", flags.ConfigPath, cfg.Theme)
+	}
+	scene.SetThemes(themes, cfg.Theme)
+	mgr.AddScene(SceneGame, scene)
 	mgr.SwitchScene(SceneGame)
 
-	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowSize(flags.WindowWidth, flags.WindowHeight)
+	ebiten.SetFullscreen(flags.Fullscreen)
 	ebiten.SetWindowTitle("spin click (friday 02)")
 	if err := ebiten.RunGame(mgr); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func CreateConfig() (Config, error) {
+func CreateConfig(pr *prompt.Prompter) (Config, error) {
 	fmt.Println("MIDI device not configured.")
 
 	fmt.Printf("\nListing MIDI devices...\n")
 	inPorts := midi.GetInPorts()
-
+	if len(inPorts) == 0 {
+		return Config{}, fmt.Errorf("no MIDI input ports found")
+	}
+	choices := make([]string, len(inPorts))
 	for i, port := range inPorts {
-		fmt.Printf("%2d: %s (#%d)\n", i, port.String(), port.Number())
+		choices[i] = fmt.Sprintf("%s (#%d)", port.String(), port.Number())
+	}
+	n, err := pr.Choose("\nChoose your MIDI device", choices)
+	if err != nil {
+		return Config{}, err
 	}
-
-	n := MustReadNumber(0, len(inPorts)-1, "\nChoose your MIDI device")
 	port := inPorts[n]
 
 	stop, err := midi.ListenTo(port, func(msg midi.Message, timestampms int32) {
@@ -138,6 +168,7 @@ func CreateConfig() (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
+	defer stop()
 
 	cfg := Config{
 		MidiDevice: port.String(),
@@ -145,10 +176,14 @@ func CreateConfig() (Config, error) {
 
 	fmt.Printf("\nMIDI device %s active. Turn knobs to print control change messages.\n", port.String())
 
-	cfg.Knob1Chan = MustReadNumber(0, 15, "\nChoose the Channel for Knob1")
-	cfg.Knob1Controller = MustReadNumber(0, 127, "\nChoose the Controller for Knob1")
-
-	stop()
+	cfg.Knob1Chan, err = pr.IntRange("\nChoose the Channel for Knob1", 0, 15)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Knob1Controller, err = pr.IntRange("\nChoose the Controller for Knob1", 0, 127)
+	if err != nil {
+		return Config{}, err
+	}
 
 	fmt.Printf("\nConfig created: \"%s\", channel %d, controller %d\n",
 		cfg.MidiDevice, cfg.Knob1Chan, cfg.Knob1Controller)
@@ -156,35 +191,8 @@ func CreateConfig() (Config, error) {
 	return cfg, nil
 }
 
-func MustReadNumber(min, max int, msg string) int {
-	punctuation := ":"
-	if strings.HasSuffix(msg, "?") {
-		punctuation = "?"
-		msg = msg[:len(msg)-1]
-	}
-
-	fmt.Printf("%s [%d-%d]%s ", msg, min, max, punctuation)
-
-try_again:
-	reader := bufio.NewReader(os.Stdin)
-	line, err := reader.ReadString('\n')
-	if err != nil {
-		panic(err)
-	}
-
-	// remove the delimeter from the string
-	line = strings.TrimSpace(line)
-
-	n, err := strconv.Atoi(line)
-	if err != nil {
-		fmt.Printf("Invalid input: %s\nPlease enter a number in the range [%d-%d]: ", err.Error(), min, max)
-		goto try_again
-	}
-
-	if n < min || n > max {
-		fmt.Printf("Invalid input: %d\nPlease enter a number in the range [%d-%d]: ", n, min, max)
-		goto try_again
+func printInPorts(inPorts midi.InPorts) {
+	for i, port := range inPorts {
+		fmt.Printf("%2d: %s (#%d)\n", i, port.String(), port.Number())
 	}
-
-	return n
 }
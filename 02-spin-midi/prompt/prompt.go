@@ -0,0 +1,174 @@
+// Package prompt asks the user simple questions on the command line.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCanceled is returned when the user enters "q" or "quit" instead of an answer.
+	ErrCanceled = errors.New("canceled by user")
+
+	// ErrClosed is returned when the input runs out before a valid answer is read.
+	ErrClosed = errors.New("input closed")
+)
+
+// Prompter reads answers from r, and writes prompts (and complaints about invalid answers) to w.
+// A single bufio.Reader is kept for the life of the Prompter, so no buffered input is lost between prompts.
+type Prompter struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+func New(r io.Reader, w io.Writer) *Prompter {
+	return &Prompter{
+		r: bufio.NewReader(r),
+		w: w,
+	}
+}
+
+// Int asks for any integer.
+func (p *Prompter) Int(msg string) (int, error) {
+	return ask(p, msg, "", "a number", nil, func(s string) (int, error) {
+		return strconv.Atoi(s)
+	})
+}
+
+// IntRange asks for an integer in the range [min,max].
+func (p *Prompter) IntRange(msg string, min, max int) (int, error) {
+	return p.intRange(msg, min, max, nil)
+}
+
+// IntRangeDefault asks for an integer in the range [min,max], using def if the answer is left blank.
+func (p *Prompter) IntRangeDefault(msg string, min, max, def int) (int, error) {
+	return p.intRange(msg, min, max, &def)
+}
+
+func (p *Prompter) intRange(msg string, min, max int, def *int) (int, error) {
+	rangeStr := fmt.Sprintf("%d-%d", min, max)
+	return ask(p, msg, rangeStr, "a number in the range ["+rangeStr+"]", def, func(s string) (int, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, err
+		}
+		if n < min || n > max {
+			return 0, fmt.Errorf("%d", n)
+		}
+		return n, nil
+	})
+}
+
+// Choose lists the choices, then asks for the index of one of them.
+func (p *Prompter) Choose(msg string, choices []string) (int, error) {
+	return p.choose(msg, choices, nil)
+}
+
+// ChooseDefault is like Choose, but uses def if the answer is left blank.
+func (p *Prompter) ChooseDefault(msg string, choices []string, def int) (int, error) {
+	return p.choose(msg, choices, &def)
+}
+
+func (p *Prompter) choose(msg string, choices []string, def *int) (int, error) {
+	if len(choices) == 0 {
+		return 0, errors.New("nothing to choose from")
+	}
+	for i, c := range choices {
+		fmt.Fprintf(p.w, "%2d: %s\n", i, c)
+	}
+	return p.intRange(msg, 0, len(choices)-1, def)
+}
+
+// YesNo asks a yes or no question, using def if the answer is left blank.
+func (p *Prompter) YesNo(msg string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	return ask(p, msg, hint, "y or n", &def, func(s string) (bool, error) {
+		switch strings.ToLower(s) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		return false, errors.New(s)
+	})
+}
+
+// ask writes the prompt, then reads lines until parse accepts one (or the user
+// cancels, or the input runs out). A blank line returns def, if it isn't nil.
+func ask[T any](p *Prompter, msg, hint, expected string, def *T, parse func(string) (T, error)) (T, error) {
+	var zero T
+
+	punctuation := ":"
+	if strings.HasSuffix(msg, "?") {
+		punctuation = "?"
+		msg = msg[:len(msg)-1]
+	}
+
+	switch {
+	case def != nil && len(hint) > 0:
+		fmt.Fprintf(p.w, "%s [%s] (default %v)%s ", msg, hint, *def, punctuation)
+	case def != nil:
+		fmt.Fprintf(p.w, "%s (default %v)%s ", msg, *def, punctuation)
+	case len(hint) > 0:
+		fmt.Fprintf(p.w, "%s [%s]%s ", msg, hint, punctuation)
+	default:
+		fmt.Fprintf(p.w, "%s%s ", msg, punctuation)
+	}
+
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return zero, err
+		}
+
+		switch strings.ToLower(line) {
+		case "":
+			if def != nil {
+				return *def, nil
+			}
+			fmt.Fprintf(p.w, "Please enter %s: ", expected)
+			continue
+		case "q", "quit":
+			return zero, ErrCanceled
+		}
+
+		v, err := parse(line)
+		if err != nil {
+			fmt.Fprintf(p.w, "Invalid input: %s\nPlease enter %s: ", unwrapNumError(err), expected)
+			continue
+		}
+		return v, nil
+	}
+}
+
+// readLine returns the next line of input with surrounding whitespace removed.
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		// last line of input is missing its delimiter, but is otherwise fine
+		err = nil
+	}
+	if err == io.EOF {
+		return "", ErrClosed
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// unwrapNumError drops the `strconv.Atoi: parsing "x": ` prefix, to keep messages short.
+func unwrapNumError(err error) string {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return fmt.Sprintf("\"%s\" is not a number", numErr.Num)
+	}
+	return err.Error()
+}
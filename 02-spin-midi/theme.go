@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Theme is a palette of named colors that the game is drawn with.
+type Theme struct {
+	Name       string     `json:"name"`
+	Background ThemeColor `json:"background"`
+	Foreground ThemeColor `json:"foreground"` // the dial
+	Success    ThemeColor `json:"success"`    // what the dial is tinted when it's turned to the goal
+}
+
+// DefaultTheme returns the theme the game is drawn with when no other is chosen.
+func DefaultTheme() Theme {
+	return Theme{
+		Name:       "default",
+		Background: ThemeColor{0x56, 0x55, 0x54, 0xff},
+		Foreground: ThemeColor{0xf6, 0xf1, 0x93, 0xee},
+		Success:    ThemeColor{0x8c, 0xe9, 0x9a, 0xff},
+	}
+}
+
+// BuiltinThemes returns the themes that are available without being added to
+// the config file, starting with DefaultTheme.
+func BuiltinThemes() []Theme {
+	return []Theme{
+		DefaultTheme(),
+		{
+			Name:       "light",
+			Background: ThemeColor{0xf4, 0xf1, 0xe8, 0xff},
+			Foreground: ThemeColor{0x3a, 0x39, 0x36, 0xff},
+			Success:    ThemeColor{0x2b, 0x8a, 0x3e, 0xff},
+		},
+	}
+}
+
+// UnmarshalJSON starts from DefaultTheme, so a theme in a config file only
+// needs the colors it changes.
+func (t *Theme) UnmarshalJSON(data []byte) error {
+	type plain Theme // without this method, to avoid recursing
+	th := plain(DefaultTheme())
+	th.Name = ""
+	if err := json.Unmarshal(data, &th); err != nil {
+		return err
+	}
+	*t = Theme(th)
+	return nil
+}
+
+// ThemeColor is a color with straight (not premultiplied) alpha. In config
+// files it's a hex string, either "#rrggbb" or "#rrggbbaa".
+type ThemeColor color.NRGBA
+
+func (c ThemeColor) RGBA() (r, g, b, a uint32) {
+	return color.NRGBA(c).RGBA()
+}
+
+func (c ThemeColor) MarshalJSON() ([]byte, error) {
+	s := fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	if c.A != 0xff {
+		s += fmt.Sprintf("%02x", c.A)
+	}
+	return json.Marshal(s)
+}
+
+func (c *ThemeColor) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || (len(hex) != 6 && len(hex) != 8) {
+		return fmt.Errorf("color \"%s\": expected #rrggbb or #rrggbbaa", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return fmt.Errorf("color \"%s\": expected #rrggbb or #rrggbbaa", s)
+	}
+	*c = ThemeColor{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}
+	return nil
+}
+
+// LerpColor blends from a (at t=0) to b (at t=1), clamping t to [0,1]. Colors
+// are blended with premultiplied alpha, so fading from a transparent color
+// doesn't darken the other one.
+func LerpColor(a, b color.Color, t float32) color.RGBA {
+	t = min32(max32(t, 0), 1)
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	mix := func(x, y uint32) uint8 {
+		return uint8((float32(x)+(float32(y)-float32(x))*t)/0x101 + 0.5)
+	}
+	return color.RGBA{mix(ar, br), mix(ag, bg), mix(ab, bb), mix(aa, ba)}
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// AudioSounds plays sound effects, synthesized when it's created, through
+// the default audio device.
+type AudioSounds struct {
+	ctx    *audio.Context
+	pcm    [soundCount][]byte
+	volume float64
+}
+
+// NewAudioSounds synthesizes every sound, ready to be played at volume (from
+// 0 for silent, to 1 for full volume).
+func NewAudioSounds(volume float64) *AudioSounds {
+	// ebiten only allows one audio context
+	ctx := audio.CurrentContext()
+	if ctx == nil {
+		ctx = audio.NewContext(sampleRate)
+	}
+	a := &AudioSounds{ctx: ctx, volume: volume}
+	for s := range a.pcm {
+		a.pcm[s] = toPCM16Stereo(synthesize(Sound(s)))
+	}
+	return a
+}
+
+// Play starts playing s, over the top of anything already playing.
+func (a *AudioSounds) Play(s Sound) {
+	if a.volume <= 0 || s < 0 || s >= soundCount {
+		return
+	}
+	// players are cheap, and once they finish, the context lets go of them
+	p := a.ctx.NewPlayerFromBytes(a.pcm[s])
+	p.SetVolume(a.volume)
+	p.Play()
+}
+
+// SetVolume sets the volume of sounds played from now on.
+func (a *AudioSounds) SetVolume(volume float64) {
+	a.volume = volume
+}
@@ -0,0 +1,352 @@
+package main
+
+import "math"
+
+// Rect is an axis-aligned rectangle, such as a bounding box. A Rect whose
+// Min is greater than its Max on either axis is empty.
+type Rect struct {
+	Min, Max Vec2D
+}
+
+// EmptyRect returns a rect that contains nothing, and that becomes the
+// bounds of whatever is added to it with Union or Extend.
+func EmptyRect() Rect {
+	inf := float32(math.Inf(1))
+	return Rect{Min: Vec2D{inf, inf}, Max: Vec2D{-inf, -inf}}
+}
+
+// BoundsOf returns the smallest rect containing every point in contours.
+func BoundsOf(contours []Contour) Rect {
+	r := EmptyRect()
+	for _, c := range contours {
+		for _, p := range c.Points {
+			r = r.Extend(p)
+		}
+	}
+	return r
+}
+
+func (r Rect) Empty() bool {
+	return r.Min.X > r.Max.X || r.Min.Y > r.Max.Y
+}
+
+func (r Rect) Size() Vec2D {
+	if r.Empty() {
+		return Vec2D{}
+	}
+	return r.Max.Sub(r.Min)
+}
+
+func (r Rect) Center() Vec2D {
+	return r.Min.Lerp(r.Max, 0.5)
+}
+
+// Contains reports whether p is inside r, or on its edge.
+func (r Rect) Contains(p Vec2D) bool {
+	return p.X >= r.Min.X && p.X <= r.Max.X && p.Y >= r.Min.Y && p.Y <= r.Max.Y
+}
+
+// Intersects reports whether r and o overlap (or touch).
+func (r Rect) Intersects(o Rect) bool {
+	return r.Min.X <= o.Max.X && o.Min.X <= r.Max.X && r.Min.Y <= o.Max.Y && o.Min.Y <= r.Max.Y
+}
+
+// Extend returns the smallest rect containing r and p.
+func (r Rect) Extend(p Vec2D) Rect {
+	return Rect{
+		Min: Vec2D{min32(r.Min.X, p.X), min32(r.Min.Y, p.Y)},
+		Max: Vec2D{max32(r.Max.X, p.X), max32(r.Max.Y, p.Y)},
+	}
+}
+
+// Union returns the smallest rect containing r and o.
+func (r Rect) Union(o Rect) Rect {
+	if o.Empty() {
+		return r
+	}
+	return r.Extend(o.Min).Extend(o.Max)
+}
+
+// Inset returns r shrunk by d on every side (or grown, if d is negative).
+func (r Rect) Inset(d float32) Rect {
+	return Rect{Min: r.Min.Add(Vec2D{d, d}), Max: r.Max.Sub(Vec2D{d, d})}
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// windingNumber returns how many times the outlines in contours wind around
+// p, with the sign depending on direction. Every contour is treated as
+// closed, as it is when filled.
+func windingNumber(contours []Contour, p Vec2D) int {
+	w := 0
+	for _, c := range contours {
+		pts := c.Points
+		for i := range pts {
+			a, b := pts[i], pts[(i+1)%len(pts)]
+			// count edges that cross the horizontal line through p, to the right of p
+			if a.Y <= p.Y {
+				if b.Y > p.Y && b.Sub(a).Cross(p.Sub(a)) > 0 {
+					w++
+				}
+			} else if b.Y <= p.Y && b.Sub(a).Cross(p.Sub(a)) < 0 {
+				w--
+			}
+		}
+	}
+	return w
+}
+
+// PointInContours reports whether p is inside the area that filling contours with rule would cover.
+func PointInContours(contours []Contour, p Vec2D, rule FillRule) bool {
+	return rule.inside(windingNumber(contours, p))
+}
+
+// distanceToSegment returns how far p is from the nearest point on the segment from a to b.
+func distanceToSegment(p, a, b Vec2D) float32 {
+	ab := b.Sub(a)
+	t := float32(0)
+	if l := ab.LengthSq(); l > 0 {
+		t = min32(1, max32(0, p.Sub(a).Dot(ab)/l))
+	}
+	return p.Distance(a.Add(ab.Scale(t)))
+}
+
+// DistanceToContours returns how far p is from the nearest outline in
+// contours. Open contours don't include the edge from their last point back
+// to their first.
+func DistanceToContours(contours []Contour, p Vec2D) float32 {
+	d := float32(math.Inf(1))
+	forEachEdge(contours, func(a, b Vec2D) bool {
+		d = min32(d, distanceToSegment(p, a, b))
+		return true
+	})
+	return d
+}
+
+// forEachEdge calls fn with the ends of each edge in contours, until fn returns false.
+func forEachEdge(contours []Contour, fn func(a, b Vec2D) bool) {
+	for _, c := range contours {
+		pts := c.Points
+		n := len(pts)
+		if !c.Closed {
+			n--
+		}
+		for i := 0; i < n; i++ {
+			if !fn(pts[i], pts[(i+1)%len(pts)]) {
+				return
+			}
+		}
+	}
+}
+
+// segmentIntersection returns how far along a0-a1 (in the range [0,1]) it
+// crosses b0-b1, if it does. Parallel segments never cross, even if they overlap.
+func segmentIntersection(a0, a1, b0, b1 Vec2D) (float32, bool) {
+	da, db := a1.Sub(a0), b1.Sub(b0)
+	denom := da.Cross(db)
+	if denom == 0 {
+		return 0, false
+	}
+	diff := b0.Sub(a0)
+	t := diff.Cross(db) / denom
+	u := diff.Cross(da) / denom
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// IsConvex reports whether points form a convex polygon: one that turns the
+// same way at every corner, and only goes around once.
+func IsConvex(points []Vec2D) bool {
+	n := len(points)
+	if n < 3 {
+		return false
+	}
+	sign := 0
+	var turned float32
+	for i := range points {
+		a, b, c := points[i], points[(i+1)%n], points[(i+2)%n]
+		ab, bc := b.Sub(a), c.Sub(b)
+		switch cross := ab.Cross(bc); {
+		case cross > 0:
+			if sign < 0 {
+				return false
+			}
+			sign = 1
+		case cross < 0:
+			if sign > 0 {
+				return false
+			}
+			sign = -1
+		}
+		turned += ab.AngleTo(bc)
+	}
+	// a star turns the same way at every corner, but goes around more than once
+	return sign != 0 && math.Abs(math.Abs(float64(turned))-twoPi) < 0.01
+}
+
+// ConvexPolygonsIntersect uses the separating axis theorem to report whether
+// two convex polygons overlap (or touch).
+func ConvexPolygonsIntersect(a, b []Vec2D) bool {
+	return !hasSeparatingAxis(a, b) && !hasSeparatingAxis(b, a)
+}
+
+// hasSeparatingAxis reports whether any edge normal of a separates a from b.
+func hasSeparatingAxis(a, b []Vec2D) bool {
+	project := func(pts []Vec2D, axis Vec2D) (lo, hi float32) {
+		lo, hi = float32(math.Inf(1)), float32(math.Inf(-1))
+		for _, p := range pts {
+			d := p.Dot(axis)
+			lo, hi = min32(lo, d), max32(hi, d)
+		}
+		return lo, hi
+	}
+
+	for i := range a {
+		axis := a[(i+1)%len(a)].Sub(a[i]).Perp()
+		aLo, aHi := project(a, axis)
+		bLo, bHi := project(b, axis)
+		if aHi < bLo || bHi < aLo {
+			return true
+		}
+	}
+	return false
+}
+
+// ContoursIntersect reports whether the areas covered by filling a and b
+// (with ruleA and ruleB) overlap. Convex polygons are tested with
+// ConvexPolygonsIntersect; anything else by looking for crossing edges, or
+// for a point of one inside the other.
+func ContoursIntersect(a []Contour, ruleA FillRule, b []Contour, ruleB FillRule) bool {
+	if !BoundsOf(a).Intersects(BoundsOf(b)) {
+		return false
+	}
+	if len(a) == 1 && len(b) == 1 && IsConvex(a[0].Points) && IsConvex(b[0].Points) {
+		return ConvexPolygonsIntersect(a[0].Points, b[0].Points)
+	}
+
+	closed := func(cs []Contour) []Contour {
+		out := make([]Contour, len(cs))
+		for i, c := range cs {
+			out[i] = Contour{Points: c.Points, Closed: true}
+		}
+		return out
+	}
+	a, b = closed(a), closed(b)
+
+	crossed := false
+	forEachEdge(a, func(a0, a1 Vec2D) bool {
+		forEachEdge(b, func(b0, b1 Vec2D) bool {
+			_, crossed = segmentIntersection(a0, a1, b0, b1)
+			return !crossed
+		})
+		return !crossed
+	})
+	if crossed {
+		return true
+	}
+
+	// with no crossing edges, either one is inside the other or they're apart
+	for _, c := range a {
+		if len(c.Points) > 0 && PointInContours(b, c.Points[0], ruleB) {
+			return true
+		}
+	}
+	for _, c := range b {
+		if len(c.Points) > 0 && PointInContours(a, c.Points[0], ruleA) {
+			return true
+		}
+	}
+	return false
+}
+
+// RayHit is where a ray first crosses an outline.
+type RayHit struct {
+	Point    Vec2D
+	Normal   Vec2D   // unit length, facing back towards the ray's origin
+	Distance float32 // from the ray's origin to Point
+}
+
+// RaycastContours returns the first place the ray from origin, heading in
+// direction dir, crosses an outline in contours within maxDist.
+func RaycastContours(contours []Contour, origin, dir Vec2D, maxDist float32) (RayHit, bool) {
+	dir = dir.Normalize()
+	if dir == (Vec2D{}) {
+		return RayHit{}, false
+	}
+	end := origin.Add(dir.Scale(maxDist))
+
+	var hit RayHit
+	found := false
+	forEachEdge(contours, func(a, b Vec2D) bool {
+		t, ok := segmentIntersection(origin, end, a, b)
+		if !ok || (found && t*maxDist >= hit.Distance) {
+			return true
+		}
+		normal := b.Sub(a).Perp().Normalize()
+		if normal.Dot(dir) > 0 {
+			normal = normal.Scale(-1)
+		}
+		hit = RayHit{Point: origin.Add(dir.Scale(t * maxDist)), Normal: normal, Distance: t * maxDist}
+		found = true
+		return true
+	})
+	return hit, found
+}
+
+// Bounds returns the shape's bounding box in world space, ignoring stroke width.
+func (s *Shape) Bounds() Rect {
+	return BoundsOf(s.WorldContours())
+}
+
+// Contains reports whether p (in world space) is on the shape: either inside
+// its fill, or within half its stroke width of its outline.
+func (s *Shape) Contains(p Vec2D) bool {
+	contours := s.WorldContours()
+	if s.Fill != nil && PointInContours(contours, p, s.FillRule) {
+		return true
+	}
+	if s.Color != nil && s.StrokeWidth > 0 {
+		return DistanceToContours(contours, p) <= s.StrokeWidth/2
+	}
+	return false
+}
+
+// Intersects reports whether the areas inside s and o overlap in world
+// space, ignoring stroke width. Shapes are treated as filled, whether or not
+// they have a Fill.
+func (s *Shape) Intersects(o *Shape) bool {
+	if s == o {
+		return len(s.WorldContours()) > 0
+	}
+	return ContoursIntersect(s.WorldContours(), s.FillRule, o.WorldContours(), o.FillRule)
+}
+
+// Raycast returns the first place the ray from origin, heading in direction
+// dir, crosses the shape's outline (in world space) within maxDist.
+func (s *Shape) Raycast(origin, dir Vec2D, maxDist float32) (RayHit, bool) {
+	return RaycastContours(s.WorldContours(), origin, dir, maxDist)
+}
+
+// PickShape returns the last shape in shapes (the one drawn on top) that contains p, or nil if none do.
+func PickShape(shapes []*Shape, p Vec2D) *Shape {
+	for i := len(shapes) - 1; i >= 0; i-- {
+		if shapes[i].Contains(p) {
+			return shapes[i]
+		}
+	}
+	return nil
+}
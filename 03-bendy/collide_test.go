@@ -0,0 +1,232 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// squareContour returns a closed contour of a square with its top-left at (x,y).
+func squareContour(x, y, size float32) Contour {
+	return Contour{Points: square(x, y, size), Closed: true}
+}
+
+func TestRect(t *testing.T) {
+	r := EmptyRect()
+	if !r.Empty() || r.Size() != (Vec2D{}) {
+		t.Errorf("expected an empty rect, got %v", r)
+	}
+	r = r.Extend(Vec2D{1, 2}).Extend(Vec2D{-1, 4})
+	if r != (Rect{Vec2D{-1, 2}, Vec2D{1, 4}}) {
+		t.Errorf("unexpected extended rect %v", r)
+	}
+	assertVec(t, r.Size(), Vec2D{2, 2})
+	assertVec(t, r.Center(), Vec2D{0, 3})
+	if r.Union(EmptyRect()) != r {
+		t.Errorf("union with an empty rect should change nothing")
+	}
+	if u := r.Union(Rect{Vec2D{5, 5}, Vec2D{6, 6}}); u != (Rect{Vec2D{-1, 2}, Vec2D{6, 6}}) {
+		t.Errorf("unexpected union %v", u)
+	}
+
+	cases := []struct {
+		Name     string
+		Other    Rect
+		Expected bool
+	}{
+		{"overlapping", Rect{Vec2D{0, 0}, Vec2D{5, 3}}, true},
+		{"touching", Rect{Vec2D{1, 4}, Vec2D{2, 5}}, true},
+		{"inside", Rect{Vec2D{-0.5, 2.5}, Vec2D{0.5, 3.5}}, true},
+		{"apart", Rect{Vec2D{2, 2}, Vec2D{3, 3}}, false},
+		{"empty", EmptyRect(), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := r.Intersects(tc.Other); got != tc.Expected {
+				t.Errorf("expected %v, got %v", tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestPointInContours(t *testing.T) {
+	// a square with a hole, both wound the same way, so the rules disagree about the hole
+	outer := squareContour(0, 0, 10)
+	inner := squareContour(3, 3, 4)
+	contours := []Contour{outer, inner}
+
+	// a bow tie crosses itself, and both lobes are inside by either rule
+	bowtie := []Contour{{Points: []Vec2D{{0, 0}, {10, 10}, {10, 0}, {0, 10}}, Closed: true}}
+
+	cases := []struct {
+		Name     string
+		Contours []Contour
+		P        Vec2D
+		Rule     FillRule
+		Expected bool
+	}{
+		{"solid nonzero", contours, Vec2D{1, 1}, FillRuleNonZero, true},
+		{"solid evenodd", contours, Vec2D{1, 1}, FillRuleEvenOdd, true},
+		{"hole nonzero", contours, Vec2D{5, 5}, FillRuleNonZero, true},
+		{"hole evenodd", contours, Vec2D{5, 5}, FillRuleEvenOdd, false},
+		{"outside", contours, Vec2D{11, 5}, FillRuleNonZero, false},
+		{"bowtie left", bowtie, Vec2D{1, 5}, FillRuleNonZero, true},
+		{"bowtie right", bowtie, Vec2D{9, 5}, FillRuleEvenOdd, true},
+		{"bowtie top", bowtie, Vec2D{5, 1}, FillRuleNonZero, false},
+		{"open contour", []Contour{{Points: outer.Points}}, Vec2D{5, 5}, FillRuleNonZero, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := PointInContours(tc.Contours, tc.P, tc.Rule); got != tc.Expected {
+				t.Errorf("expected %v, got %v", tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestDistanceToContours(t *testing.T) {
+	closed := []Contour{squareContour(0, 0, 10)}
+	open := []Contour{{Points: closed[0].Points}}
+
+	assertNear(t, "inside", DistanceToContours(closed, Vec2D{2, 5}), 2)
+	assertNear(t, "corner", DistanceToContours(closed, Vec2D{13, 14}), 5)
+	// an open contour has no left edge
+	assertNear(t, "open", DistanceToContours(open, Vec2D{2, 5}), 5)
+}
+
+func TestIsConvex(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Points   []Vec2D
+		Expected bool
+	}{
+		{"square", square(0, 0, 1), true},
+		{"reversed", []Vec2D{{0, 0}, {0, 1}, {1, 1}, {1, 0}}, true},
+		{"collinear point", []Vec2D{{0, 0}, {1, 0}, {2, 0}, {2, 2}}, true},
+		{"arrow", []Vec2D{{0, 0}, {2, 1}, {0, 2}, {1, 1}}, false},
+		{"star", []Vec2D{{0, -10}, {6, 8}, {-9, -3}, {9, -3}, {-6, 8}}, false},
+		{"line", []Vec2D{{0, 0}, {1, 0}}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := IsConvex(tc.Points); got != tc.Expected {
+				t.Errorf("expected %v, got %v", tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestContoursIntersect(t *testing.T) {
+	// an L shape is concave, so its bounds overlap things it doesn't touch
+	ell := []Contour{{Points: []Vec2D{{0, 0}, {2, 0}, {2, 8}, {10, 8}, {10, 10}, {0, 10}}, Closed: true}}
+	ring := []Contour{squareContour(0, 0, 10), squareContour(2, 2, 6)}
+	diamond := []Contour{{Points: []Vec2D{{5, 0}, {10, 5}, {5, 10}, {0, 5}}, Closed: true}}
+
+	cases := []struct {
+		Name     string
+		A, B     []Contour
+		RuleB    FillRule
+		Expected bool
+	}{
+		{"convex overlap", []Contour{squareContour(0, 0, 2)}, []Contour{squareContour(1, 1, 2)}, FillRuleNonZero, true},
+		{"convex apart", []Contour{squareContour(0, 0, 2)}, []Contour{squareContour(3, 0, 2)}, FillRuleNonZero, false},
+		// bounds overlap, but the diagonal edges of the diamond separate them
+		{"convex diagonal gap", diamond, []Contour{squareContour(0, 0, 2)}, FillRuleNonZero, false},
+		{"convex inside", []Contour{squareContour(0, 0, 10)}, []Contour{squareContour(4, 4, 1)}, FillRuleNonZero, true},
+		{"concave crossing", ell, []Contour{squareContour(1, 1, 2)}, FillRuleNonZero, true},
+		{"concave in the gap", ell, []Contour{squareContour(4, 2, 3)}, FillRuleNonZero, false},
+		{"concave inside", ell, []Contour{squareContour(0.5, 9, 0.5)}, FillRuleNonZero, true},
+		{"in the hole", []Contour{squareContour(4, 4, 1)}, ring, FillRuleEvenOdd, false},
+		{"hole filled", []Contour{squareContour(4, 4, 1)}, ring, FillRuleNonZero, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := ContoursIntersect(tc.A, FillRuleNonZero, tc.B, tc.RuleB); got != tc.Expected {
+				t.Errorf("expected %v, got %v", tc.Expected, got)
+			}
+			if got := ContoursIntersect(tc.B, tc.RuleB, tc.A, FillRuleNonZero); got != tc.Expected {
+				t.Errorf("reversed: expected %v, got %v", tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestRaycastContours(t *testing.T) {
+	contours := []Contour{squareContour(0, 0, 10), squareContour(20, 0, 10)}
+
+	hit, ok := RaycastContours(contours, Vec2D{-5, 5}, Vec2D{2, 0}, 100)
+	if !ok {
+		t.Fatalf("expected a hit")
+	}
+	assertVec(t, hit.Point, Vec2D{0, 5})
+	assertVec(t, hit.Normal, Vec2D{-1, 0})
+	assertNear(t, "distance", hit.Distance, 5)
+
+	// from inside, the ray hits the far wall, and the normal still faces back
+	hit, ok = RaycastContours(contours, Vec2D{5, 5}, Vec2D{0, -1}, 100)
+	if !ok {
+		t.Fatalf("expected a hit from inside")
+	}
+	assertVec(t, hit.Point, Vec2D{5, 0})
+	assertVec(t, hit.Normal, Vec2D{0, 1})
+
+	if _, ok := RaycastContours(contours, Vec2D{-5, 5}, Vec2D{1, 0}, 4); ok {
+		t.Errorf("expected no hit within max distance")
+	}
+	if _, ok := RaycastContours(contours, Vec2D{15, 5}, Vec2D{0, 1}, 100); ok {
+		t.Errorf("expected no hit between the squares")
+	}
+}
+
+func TestShape_HitTesting(t *testing.T) {
+	// a 10x10 square, scaled up 2x and rotated a quarter turn around its center, which is placed at (100,100)
+	s := NewShape(square(0, 0, 10), nil, 0)
+	s.Fill = SolidPaint{Color: color.White}
+	s.Xfm.SetPivot(Vec2D{5, 5})
+	s.Xfm.SetPos(Vec2D{100, 100})
+	s.Xfm.SetUniformScale(2)
+	s.Xfm.SetRot(math.Pi / 2)
+
+	b := s.Bounds()
+	assertVec(t, b.Min, Vec2D{90, 90})
+	assertVec(t, b.Max, Vec2D{110, 110})
+
+	if !s.Contains(Vec2D{109, 91}) || s.Contains(Vec2D{111, 100}) {
+		t.Errorf("Contains doesn't match the transformed square")
+	}
+
+	// without a fill, only the stroke can be hit
+	s.Fill = nil
+	s.Color = color.White
+	s.StrokeWidth = 4
+	if s.Contains(Vec2D{100, 100}) || !s.Contains(Vec2D{111, 100}) {
+		t.Errorf("Contains doesn't match the stroke")
+	}
+
+	other := NewShape(square(0, 0, 5), nil, 0)
+	other.Fill = SolidPaint{Color: color.White}
+	other.Xfm.SetPos(Vec2D{108, 108})
+	if !s.Intersects(other) {
+		t.Errorf("expected shapes to intersect")
+	}
+	other.Xfm.SetPos(Vec2D{111, 100})
+	if s.Intersects(other) {
+		t.Errorf("expected shapes not to intersect")
+	}
+
+	hit, ok := s.Raycast(Vec2D{0, 100}, Vec2D{1, 0}, 1000)
+	if !ok {
+		t.Fatalf("expected a hit")
+	}
+	assertVec(t, hit.Point, Vec2D{90, 100})
+
+	if PickShape([]*Shape{s, other}, Vec2D{112, 101}) != other {
+		t.Errorf("expected the top shape to be picked")
+	}
+	if PickShape([]*Shape{s, other}, Vec2D{0, 0}) != nil {
+		t.Errorf("expected nothing to be picked")
+	}
+}
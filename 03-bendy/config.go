@@ -2,10 +2,61 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
+// configName is the name of this experiment's config file.
+const configName = "03-bendy.json"
+
+// DefaultConfigPath returns the config path within the user's config folder
+// (e.g. $XDG_CONFIG_HOME/friday/03-bendy.json), or configName in the current
+// working directory if the user's config folder can't be determined.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return configName
+	}
+	return filepath.Join(dir, "friday", configName)
+}
+
 type Config struct {
+	Profiles []Profile `json:"profiles"`
+	Themes   []Theme   `json:"themes,omitempty"` // added to the built-in themes, or replacing ones with the same name
+	Theme    string    `json:"theme,omitempty"`  // name of the theme to use; empty for the default
+	Volume   *float64  `json:"volume,omitempty"` // of sound effects, [0,1]; nil for defaultVolume
+
+	// MidiOut sends sounds to a MIDI output port, alongside the built-in
+	// sounds (or instead of them, if Volume is 0). nil to send nothing.
+	MidiOut *MidiOutConfig `json:"midi_out,omitempty"`
+}
+
+// MidiOutConfig maps the game's sounds to MIDI messages, to play them on an
+// external synth.
+type MidiOutConfig struct {
+	Device string            `json:"device"` // output port; like midi_device, it only needs to be part of the port's name
+	Events []MidiEventConfig `json:"events"`
+}
+
+// MidiEventConfig is a note or control change to send whenever a sound plays.
+type MidiEventConfig struct {
+	Sound      string `json:"sound"` // "tick" (the dial turned a step) or "click" (the dial reached the goal)
+	Channel    int    `json:"channel"`
+	Note       *int   `json:"note,omitempty"`        // note to play; set either this or controller
+	Velocity   int    `json:"velocity,omitempty"`    // of the note; 0 for defaultVelocity
+	DurationMs int    `json:"duration_ms,omitempty"` // how long the note plays; 0 for defaultNoteDuration
+	Controller *int   `json:"controller,omitempty"`  // controller to send a change to; set either this or note
+	Value      int    `json:"value,omitempty"`       // sent to the controller
+}
+
+// defaultVolume is the volume of sound effects when the config doesn't set one.
+const defaultVolume = 0.5
+
+// Profile is the set of bindings for a single MIDI controller.
+type Profile struct {
+	Name       string       `json:"name"`
 	MidiDevice string       `json:"midi_device"`
 	Knobs      []KnobConfig `json:"knobs"`
 }
@@ -15,8 +66,203 @@ type KnobConfig struct {
 	Controller int `json:"controller"`
 }
 
+// Validate returns an error if any config values are missing or out of range.
+func (c Config) Validate() error {
+	if len(c.Profiles) == 0 {
+		return fmt.Errorf("no profiles")
+	}
+	names := make(map[string]bool, len(c.Profiles))
+	for i, p := range c.Profiles {
+		if len(p.Name) == 0 {
+			return fmt.Errorf("profiles[%d]: name is empty", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("profiles[%d]: duplicate name \"%s\"", i, p.Name)
+		}
+		names[p.Name] = true
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("profile \"%s\": %w", p.Name, err)
+		}
+	}
+
+	themes := make(map[string]bool, len(c.Themes))
+	for i, t := range c.Themes {
+		if len(t.Name) == 0 {
+			return fmt.Errorf("themes[%d]: name is empty", i)
+		}
+		if themes[t.Name] {
+			return fmt.Errorf("themes[%d]: duplicate name \"%s\"", i, t.Name)
+		}
+		themes[t.Name] = true
+	}
+	if _, err := c.SelectTheme(""); err != nil {
+		return err
+	}
+	if c.Volume != nil && (*c.Volume < 0 || *c.Volume > 1) {
+		return fmt.Errorf("volume %v is outside the range [0-1]", *c.Volume)
+	}
+	if c.MidiOut != nil {
+		if err := c.MidiOut.Validate(); err != nil {
+			return fmt.Errorf("midi_out: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if any MIDI output values are missing or out of range.
+func (m MidiOutConfig) Validate() error {
+	if len(m.Device) == 0 {
+		return fmt.Errorf("device is empty")
+	}
+	for i, e := range m.Events {
+		if err := e.Validate(); err != nil {
+			return fmt.Errorf("events[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if any event values are missing or out of range.
+func (e MidiEventConfig) Validate() error {
+	if _, err := parseSound(e.Sound); err != nil {
+		return err
+	}
+	if e.Channel < 0 || e.Channel > 15 {
+		return fmt.Errorf("channel %d is outside the range [0-15]", e.Channel)
+	}
+	if (e.Note == nil) == (e.Controller == nil) {
+		return fmt.Errorf("expected either a note or a controller")
+	}
+	if e.Note != nil {
+		if *e.Note < 0 || *e.Note > 127 {
+			return fmt.Errorf("note %d is outside the range [0-127]", *e.Note)
+		}
+		if e.Velocity < 0 || e.Velocity > 127 {
+			return fmt.Errorf("velocity %d is outside the range [0-127]", e.Velocity)
+		}
+		if e.DurationMs < 0 {
+			return fmt.Errorf("duration_ms %d is negative", e.DurationMs)
+		}
+	}
+	if e.Controller != nil {
+		if *e.Controller < 0 || *e.Controller > 127 {
+			return fmt.Errorf("controller %d is outside the range [0-127]", *e.Controller)
+		}
+		if e.Value < 0 || e.Value > 127 {
+			return fmt.Errorf("value %d is outside the range [0-127]", e.Value)
+		}
+	}
+	return nil
+}
+
+// SoundVolume returns the volume to play sound effects at.
+func (c Config) SoundVolume() float64 {
+	if c.Volume == nil {
+		return defaultVolume
+	}
+	return *c.Volume
+}
+
+// Validate returns an error if any profile values are missing or out of range.
+func (p Profile) Validate() error {
+	if len(p.MidiDevice) == 0 {
+		return fmt.Errorf("midi_device is empty")
+	}
+	for i, knob := range p.Knobs {
+		if knob.Channel < 0 || knob.Channel > 15 {
+			return fmt.Errorf("knobs[%d]: channel %d is outside the range [0-15]", i, knob.Channel)
+		}
+		if knob.Controller < 0 || knob.Controller > 127 {
+			return fmt.Errorf("knobs[%d]: controller %d is outside the range [0-127]", i, knob.Controller)
+		}
+	}
+	return nil
+}
+
+// SelectProfile returns the profile with the given name. If name is empty, then it returns
+// the first profile whose MIDI device matches one of the given port names. Like midi.FindInPort,
+// a device matches a port if the port's name contains the device name.
+func (c Config) SelectProfile(name string, portNames []string) (Profile, error) {
+	if len(name) > 0 {
+		for _, p := range c.Profiles {
+			if p.Name == name {
+				return p, nil
+			}
+		}
+		return Profile{}, fmt.Errorf("profile \"%s\" not found", name)
+	}
+
+	for _, p := range c.Profiles {
+		for _, port := range portNames {
+			if strings.Contains(port, p.MidiDevice) {
+				return p, nil
+			}
+		}
+	}
+	return Profile{}, fmt.Errorf("no profile matches an available MIDI device (%s)", strings.Join(portNames, ", "))
+}
+
+// AllThemes returns the built-in themes, followed by the config's themes.
+// A config theme with the same name as a built-in one replaces it.
+func (c Config) AllThemes() []Theme {
+	themes := BuiltinThemes()
+	for _, t := range c.Themes {
+		i := findTheme(themes, t.Name)
+		if i < 0 {
+			themes = append(themes, t)
+		} else {
+			themes[i] = t
+		}
+	}
+	return themes
+}
+
+// SelectTheme returns the theme with the given name, or if name is empty, the
+// config's chosen theme, or DefaultTheme if it doesn't choose one.
+func (c Config) SelectTheme(name string) (Theme, error) {
+	if len(name) == 0 {
+		name = c.Theme
+	}
+	if len(name) == 0 {
+		name = DefaultTheme().Name
+	}
+	themes := c.AllThemes()
+	i := findTheme(themes, name)
+	if i < 0 {
+		return Theme{}, fmt.Errorf("theme \"%s\" not found", name)
+	}
+	return themes[i], nil
+}
+
+// findTheme returns the index of the theme with the given name, or -1.
+func findTheme(themes []Theme, name string) int {
+	for i, t := range themes {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetProfile replaces the profile with the same name as p, or adds p if there isn't one.
+func (c *Config) SetProfile(p Profile) {
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == p.Name {
+			c.Profiles[i] = p
+			return
+		}
+	}
+	c.Profiles = append(c.Profiles, p)
+}
+
 func LoadConfig(path string) (Config, error) {
-	var config Config
+	var config struct {
+		Config
+
+		// single device config, from before profiles were added
+		MidiDevice string       `json:"midi_device"`
+		Knobs      []KnobConfig `json:"knobs"`
+	}
 
 	fp, err := os.Open(path)
 	if err != nil {
@@ -24,10 +270,21 @@ func LoadConfig(path string) (Config, error) {
 	}
 	defer fp.Close()
 	err = json.NewDecoder(fp).Decode(&config)
-	return config, err
+
+	if len(config.MidiDevice) > 0 {
+		config.SetProfile(Profile{
+			Name:       config.MidiDevice,
+			MidiDevice: config.MidiDevice,
+			Knobs:      config.Knobs,
+		})
+	}
+	return config.Config, err
 }
 
 func SaveConfig(path string, config Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
 	fp, err := os.Create(path)
 	if err != nil {
 		return err
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// validProfile returns a profile that passes Validate.
+func validProfile(name string) Profile {
+	return Profile{
+		Name:       name,
+		MidiDevice: "Arturia MiniLab mkII",
+		Knobs:      []KnobConfig{{Channel: 0, Controller: 112}, {Channel: 15, Controller: 127}},
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	withKnob := func(k KnobConfig) Config {
+		p := validProfile("p")
+		p.Knobs = append(p.Knobs, k)
+		return Config{Profiles: []Profile{p}}
+	}
+	volume := func(v float64) *float64 { return &v }
+
+	cases := []struct {
+		Name   string
+		Config Config
+		Valid  bool
+	}{
+		{"valid", Config{Profiles: []Profile{validProfile("a"), validProfile("b")}}, true},
+		{"no profiles", Config{}, false},
+		{"empty name", Config{Profiles: []Profile{validProfile("")}}, false},
+		{"duplicate name", Config{Profiles: []Profile{validProfile("a"), validProfile("a")}}, false},
+		{"empty device", Config{Profiles: []Profile{{Name: "a"}}}, false},
+		{"channel below range", withKnob(KnobConfig{Channel: -1, Controller: 1}), false},
+		{"channel above range", withKnob(KnobConfig{Channel: 16, Controller: 1}), false},
+		{"controller below range", withKnob(KnobConfig{Channel: 0, Controller: -1}), false},
+		{"controller above range", withKnob(KnobConfig{Channel: 0, Controller: 128}), false},
+		{"volume in range", Config{Profiles: []Profile{validProfile("a")}, Volume: volume(1)}, true},
+		{"volume above range", Config{Profiles: []Profile{validProfile("a")}, Volume: volume(1.5)}, false},
+		{"unknown theme", Config{Profiles: []Profile{validProfile("a")}, Theme: "neon"}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.Config.Validate()
+			if tc.Valid && err != nil {
+				t.Errorf("expected valid, got %v", err)
+			}
+			if !tc.Valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestConfig_SelectProfile(t *testing.T) {
+	cfg := Config{Profiles: []Profile{
+		{Name: "mini", MidiDevice: "MiniLab"},
+		{Name: "nano", MidiDevice: "nanoKONTROL2"},
+		{Name: "nano-alt", MidiDevice: "nanoKONTROL2"},
+	}}
+
+	cases := []struct {
+		Name     string
+		Select   string
+		Ports    []string
+		Expected string // profile name; empty for an error
+	}{
+		{"by name", "nano", nil, "nano"},
+		{"by name ignores ports", "nano-alt", []string{"Arturia MiniLab mkII 0"}, "nano-alt"},
+		{"unknown name", "launchkey", []string{"Arturia MiniLab mkII 0"}, ""},
+		{"device is part of port name", "", []string{"Midi Through 14:0", "nanoKONTROL2:nanoKONTROL2 MIDI 1 20:0"}, "nano"},
+		{"first matching profile wins", "", []string{"nanoKONTROL2 1", "Arturia MiniLab mkII 0"}, "mini"},
+		{"device match is case sensitive", "", []string{"arturia minilab"}, ""},
+		{"no ports", "", nil, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			p, err := cfg.SelectProfile(tc.Select, tc.Ports)
+			if len(tc.Expected) == 0 {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Name != tc.Expected {
+				t.Errorf("expected %s, got %s", tc.Expected, p.Name)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	knobs := []KnobConfig{{Channel: 0, Controller: 112}, {Channel: 1, Controller: 74}}
+
+	cases := []struct {
+		Name     string
+		JSON     string
+		Expected []Profile
+	}{
+		{
+			"profiles",
+			`{"profiles": [{"name": "a", "midi_device": "MiniLab", "knobs": [{"channel": 0, "controller": 112}, {"channel": 1, "controller": 74}]}]}`,
+			[]Profile{{Name: "a", MidiDevice: "MiniLab", Knobs: knobs}},
+		},
+		{
+			"legacy",
+			`{"midi_device": "MiniLab", "knobs": [{"channel": 0, "controller": 112}, {"channel": 1, "controller": 74}]}`,
+			[]Profile{{Name: "MiniLab", MidiDevice: "MiniLab", Knobs: knobs}},
+		},
+		{
+			"legacy added after profiles",
+			`{"midi_device": "MiniLab", "knobs": [{"channel": 0, "controller": 112}, {"channel": 1, "controller": 74}],
+			  "profiles": [{"name": "nano", "midi_device": "nanoKONTROL2"}]}`,
+			[]Profile{{Name: "nano", MidiDevice: "nanoKONTROL2"}, {Name: "MiniLab", MidiDevice: "MiniLab", Knobs: knobs}},
+		},
+		{
+			"legacy replaces profile with its name",
+			`{"midi_device": "MiniLab", "knobs": [{"channel": 0, "controller": 112}, {"channel": 1, "controller": 74}],
+			  "profiles": [{"name": "MiniLab", "midi_device": "old"}, {"name": "nano", "midi_device": "nanoKONTROL2"}]}`,
+			[]Profile{{Name: "MiniLab", MidiDevice: "MiniLab", Knobs: knobs}, {Name: "nano", MidiDevice: "nanoKONTROL2"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), configName)
+			if err := os.WriteFile(path, []byte(tc.JSON), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg.Profiles, tc.Expected) {
+				t.Errorf("expected %+v, got %+v", tc.Expected, cfg.Profiles)
+			}
+
+			// saving writes the migrated profiles, and drops the legacy fields
+			if err := SaveConfig(path, cfg); err != nil {
+				t.Fatal(err)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(b, &fields); err != nil {
+				t.Fatal(err)
+			}
+			for _, legacy := range []string{"midi_device", "knobs"} {
+				if _, ok := fields[legacy]; ok {
+					t.Errorf("expected legacy field %s not to be saved, got %s", legacy, b)
+				}
+			}
+			saved, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(saved.Profiles, tc.Expected) {
+				t.Errorf("expected the saved config to load the same profiles, got %+v", saved.Profiles)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error for a missing file, got %v", err)
+	}
+
+	path := filepath.Join(dir, configName)
+	if err := os.WriteFile(path, []byte(`{"profiles": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err == nil || os.IsNotExist(err) {
+		t.Errorf("expected a syntax error, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+
+	"github.com/danbrakeley/friday/03-bendy/prompt"
+)
+
+// RunConfigCmd is the "friday-config" subcommand, which creates a profile without starting the game.
+// Anything not given on the command line is asked for on stdin, so with enough flags it can be
+// run from a script. JSON output goes to stdout, and everything else goes to stderr.
+func RunConfigCmd(args []string) error {
+	fs := flag.NewFlagSet("friday-config", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s friday-config [flags]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Creates a profile, and prints it as JSON (or saves it to the config file, with -write).\n\n")
+		fs.PrintDefaults()
+	}
+	var (
+		configPath  = fs.String("config", DefaultConfigPath(), "path to the config file (used with -write)")
+		list        = fs.Bool("list", false, "print the available MIDI input ports as JSON and exit")
+		device      = fs.String("device", "", "use the first MIDI input port whose name contains this string")
+		deviceRegex = fs.String("device-regex", "", "use the first MIDI input port whose name matches this regular expression")
+		name        = fs.String("name", "", "profile name (default: the MIDI device)")
+		write       = fs.Bool("write", false, "add the profile to the config file (replacing any profile with the same name)")
+		knobs       knobFlags
+	)
+	fs.Var(&knobs, "knob", "knob binding as channel:controller (repeat for each knob, in order)")
+	fs.Parse(args)
+
+	inPorts := midi.GetInPorts()
+
+	if *list {
+		type portJSON struct {
+			Number int    `json:"number"`
+			Name   string `json:"name"`
+		}
+		ports := make([]portJSON, len(inPorts))
+		for i, port := range inPorts {
+			ports[i] = portJSON{Number: port.Number(), Name: port.String()}
+		}
+		return printJSON(ports)
+	}
+
+	if len(inPorts) == 0 {
+		return fmt.Errorf("no MIDI input ports found")
+	}
+
+	pr := prompt.New(os.Stdin, os.Stderr)
+
+	var port drivers.In
+	p := Profile{Name: *name}
+	if len(*device) > 0 || len(*deviceRegex) > 0 {
+		n, err := matchPort(inPortNames(), *device, *deviceRegex)
+		if err != nil {
+			return err
+		}
+		port = inPorts[n]
+		// a substring is kept as is, since it will still match if the port number changes
+		p.MidiDevice = *device
+		if len(p.MidiDevice) == 0 {
+			p.MidiDevice = port.String()
+		}
+	} else {
+		var err error
+		port, err = ChooseInPort(pr, inPorts)
+		if err != nil {
+			return err
+		}
+		p.MidiDevice = port.String()
+	}
+	if len(p.Name) == 0 {
+		p.Name = p.MidiDevice
+	}
+
+	if len(knobs) > 0 {
+		p.Knobs = knobs
+	} else {
+		var err error
+		p.Knobs, err = ReadKnobs(pr, os.Stderr, port)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if *write {
+		cfg, err := LoadConfig(*configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("LoadConfig(%s): %w", *configPath, err)
+		}
+		cfg.SetProfile(p)
+		if err := SaveConfig(*configPath, cfg); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Saved profile \"%s\" to %s\n", p.Name, *configPath)
+	}
+
+	return printJSON(p)
+}
+
+// matchPort returns the index of the first port name that contains substr
+// (if not empty), and matches the regular expression pattern (if not empty).
+func matchPort(portNames []string, substr, pattern string) (int, error) {
+	var re *regexp.Regexp
+	if len(pattern) > 0 {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return 0, fmt.Errorf("invalid device regex: %w", err)
+		}
+	}
+
+	for i, name := range portNames {
+		if len(substr) > 0 && !strings.Contains(name, substr) {
+			continue
+		}
+		if re != nil && !re.MatchString(name) {
+			continue
+		}
+		return i, nil
+	}
+
+	return 0, fmt.Errorf("no MIDI input port matches (available: %s)", strings.Join(portNames, ", "))
+}
+
+func printJSON(v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// knobFlags is a flag.Value that collects repeated "channel:controller" knob bindings.
+type knobFlags []KnobConfig
+
+func (k *knobFlags) String() string {
+	if k == nil {
+		return ""
+	}
+	strs := make([]string, len(*k))
+	for i, knob := range *k {
+		strs[i] = fmt.Sprintf("%d:%d", knob.Channel, knob.Controller)
+	}
+	return strings.Join(strs, ",")
+}
+
+func (k *knobFlags) Set(s string) error {
+	if len(*k) >= KNOB_COUNT {
+		return fmt.Errorf("too many knobs (max %d)", KNOB_COUNT)
+	}
+	ch, ctrl, ok := strings.Cut(s, ":")
+	if !ok {
+		return fmt.Errorf("expected channel:controller, got \"%s\"", s)
+	}
+	var knob KnobConfig
+	var err error
+	if knob.Channel, err = strconv.Atoi(ch); err != nil {
+		return fmt.Errorf("invalid channel: %w", err)
+	}
+	if knob.Controller, err = strconv.Atoi(ctrl); err != nil {
+		return fmt.Errorf("invalid controller: %w", err)
+	}
+	*k = append(*k, knob)
+	return nil
+}
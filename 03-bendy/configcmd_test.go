@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2/drivers"
+
+	"github.com/danbrakeley/friday/03-bendy/prompt"
+)
+
+func TestMatchPort(t *testing.T) {
+	ports := []string{
+		"Midi Through:Midi Through Port-0 14:0",
+		"Arturia MiniLab mkII:Arturia MiniLab mkII MIDI 1 20:0",
+		"nanoKONTROL2:nanoKONTROL2 MIDI 1 24:0",
+		"nanoKONTROL2:nanoKONTROL2 MIDI 1 28:0",
+	}
+
+	cases := []struct {
+		Name     string
+		Substr   string
+		Pattern  string
+		Expected int // -1 for an error
+	}{
+		{"substring", "MiniLab", "", 1},
+		{"regex", "", `^nano.* 28:0$`, 3},
+		{"substring and regex", "nano", `24:\d+$`, 2},
+		{"ambiguous takes the first", "nanoKONTROL2", "", 2},
+		{"ambiguous regex takes the first", "", "MIDI 1", 1},
+		{"no substring match", "Launchkey", "", -1},
+		{"no regex match", "", `^Launchkey`, -1},
+		{"substring matches but regex doesn't", "MiniLab", `^nano`, -1},
+		{"bad regex", "", `nano(`, -1},
+		{"case sensitive", "minilab", "", -1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			n, err := matchPort(ports, tc.Substr, tc.Pattern)
+			if tc.Expected < 0 {
+				if err == nil {
+					t.Errorf("expected an error, got port %d", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tc.Expected {
+				t.Errorf("expected port %d, got %d", tc.Expected, n)
+			}
+		})
+	}
+}
+
+func TestKnobFlags_Set(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Args     []string
+		Expected []KnobConfig // nil if the last arg is an error
+	}{
+		{"one", []string{"0:112"}, []KnobConfig{{0, 112}}},
+		{"in order", []string{"1:74", "0:112", "15:127"}, []KnobConfig{{1, 74}, {0, 112}, {15, 127}}},
+		{"one per knob", []string{"0:1", "0:2", "0:3", "0:4"}, []KnobConfig{{0, 1}, {0, 2}, {0, 3}, {0, 4}}},
+		{"too many", []string{"0:1", "0:2", "0:3", "0:4", "0:5"}, nil},
+		{"no colon", []string{"112"}, nil},
+		{"empty", []string{""}, nil},
+		{"garbage channel", []string{"a:112"}, nil},
+		{"garbage controller", []string{"0:b"}, nil},
+		{"extra colon", []string{"0:1:2"}, nil},
+		{"comma separated", []string{"0:1,0:2"}, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var k knobFlags
+			var err error
+			for _, arg := range tc.Args {
+				if err = k.Set(arg); err != nil {
+					break
+				}
+			}
+			if tc.Expected == nil {
+				if err == nil {
+					t.Errorf("expected an error, got %v", k)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual([]KnobConfig(k), tc.Expected) {
+				t.Errorf("expected %v, got %v", tc.Expected, k)
+			}
+			if s := k.String(); s != strings.Join(tc.Args, ",") {
+				t.Errorf("expected String() to give back the args, got %s", s)
+			}
+		})
+	}
+}
+
+// fakeInPort is a MIDI input port that never sends anything.
+type fakeInPort struct {
+	open, listening bool
+}
+
+func (p *fakeInPort) Open() error             { p.open = true; return nil }
+func (p *fakeInPort) Close() error            { p.open = false; return nil }
+func (p *fakeInPort) IsOpen() bool            { return p.open }
+func (p *fakeInPort) Number() int             { return 0 }
+func (p *fakeInPort) String() string          { return "fake" }
+func (p *fakeInPort) Underlying() interface{} { return nil }
+
+func (p *fakeInPort) Listen(onMsg func(msg []byte, milliseconds int32), config drivers.ListenConfig) (func(), error) {
+	p.listening = true
+	return func() { p.listening = false }, nil
+}
+
+func TestReadKnobs_ClosedInput(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Input string
+	}{
+		{"nothing", ""},
+		{"partway through", "0\n112\n1\n"},
+		{"after garbage", "0\n112\nabc\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			port := &fakeInPort{}
+			pr := prompt.New(strings.NewReader(tc.Input), io.Discard)
+			knobs, err := ReadKnobs(pr, io.Discard, port)
+			if !errors.Is(err, prompt.ErrClosed) {
+				t.Errorf("expected %v, got %v (and knobs %v)", prompt.ErrClosed, err, knobs)
+			}
+			if port.listening {
+				t.Errorf("expected to stop listening to the port")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const configPollInterval = 500 * time.Millisecond
+
+// ConfigWatcher polls a config file, and reloads it in a Go routine
+// whenever the file's modification time changes.
+type ConfigWatcher struct {
+	path   string
+	load   func(path string) (Config, error)
+	apply  func(cfg Config) error
+	chLoad chan configResult
+	done   chan struct{}
+	err    error
+}
+
+type configResult struct {
+	Cfg Config
+	Err error
+}
+
+// NewConfigWatcher starts watching the file at path. When a change is seen,
+// load is called (from the watcher's Go routine, so it must be thread safe),
+// and then the resulting config is passed to apply during the next Update.
+func NewConfigWatcher(path string, load func(path string) (Config, error), apply func(cfg Config) error) *ConfigWatcher {
+	w := &ConfigWatcher{
+		path:   path,
+		load:   load,
+		apply:  apply,
+		chLoad: make(chan configResult),
+		done:   make(chan struct{}),
+	}
+	// see when the file was last changed before returning, so changes made
+	// right after this aren't mistaken for the starting state
+	var lastMod time.Time
+	if fi, err := os.Stat(path); err == nil {
+		lastMod = fi.ModTime()
+	}
+	go w.poll(lastMod)
+	return w
+}
+
+func (w *ConfigWatcher) Close() {
+	close(w.done)
+}
+
+func (w *ConfigWatcher) poll(lastMod time.Time) {
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		// a missing file is likely an editor in the middle of saving, so just wait for it to return
+		fi, err := os.Stat(w.path)
+		if err != nil || fi.ModTime().Equal(lastMod) {
+			continue
+		}
+		lastMod = fi.ModTime()
+
+		cfg, err := w.load(w.path)
+		select {
+		case w.chLoad <- configResult{Cfg: cfg, Err: err}:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Update is called by the main thread. If a reloaded config is waiting, it is passed to apply.
+// Any error from loading or applying is kept until the next successful reload (see Err).
+func (w *ConfigWatcher) Update() {
+	select {
+	case r := <-w.chLoad:
+		if r.Err != nil {
+			w.err = fmt.Errorf("reload %s: %w", w.path, r.Err)
+			return
+		}
+		if err := w.apply(r.Cfg); err != nil {
+			w.err = fmt.Errorf("apply %s: %w", w.path, err)
+			return
+		}
+		w.err = nil
+		fmt.Printf("Reloaded %s\n", w.path)
+	default:
+	}
+}
+
+// Err returns the error from the most recent reload, or nil if it succeeded.
+func (w *ConfigWatcher) Err() error {
+	return w.err
+}
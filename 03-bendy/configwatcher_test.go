@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher_ReportsReloadErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), configName)
+	valid := func(device string) Config {
+		p := validProfile("p")
+		p.MidiDevice = device
+		return Config{Profiles: []Profile{p}}
+	}
+	if err := SaveConfig(path, valid("first")); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, start, start); err != nil {
+		t.Fatal(err)
+	}
+
+	var applied []string
+	w := NewConfigWatcher(path, func(path string) (Config, error) {
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			return Config{}, err
+		}
+		return cfg, cfg.Validate()
+	}, func(cfg Config) error {
+		applied = append(applied, cfg.Profiles[0].MidiDevice)
+		return nil
+	})
+	defer w.Close()
+
+	// rewrite writes contents to the file, with a modification time the
+	// watcher hasn't seen yet, then calls Update until the reload arrives
+	step := 0
+	rewrite := func(contents string) {
+		t.Helper()
+		step++
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mod := start.Add(time.Duration(step) * time.Minute)
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+
+		err, n := w.Err(), len(applied)
+		for deadline := time.Now().Add(5 * configPollInterval); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			w.Update()
+			if len(applied) != n || w.Err() != err {
+				return
+			}
+		}
+		t.Fatalf("step %d: no reload seen", step)
+	}
+
+	rewrite(`{"profiles": [{"name": "p", "midi_device": "second"}]}`)
+	if len(applied) != 1 || applied[0] != "second" || w.Err() != nil {
+		t.Fatalf("expected the valid config to be applied, got applied %v, err %v", applied, w.Err())
+	}
+
+	// out of range values are caught by the load func, so aren't applied
+	rewrite(`{"profiles": [{"name": "p", "midi_device": "third", "knobs": [{"channel": 16}]}]}`)
+	if len(applied) != 1 || w.Err() == nil {
+		t.Fatalf("expected the invalid config to be reported and not applied, got applied %v, err %v", applied, w.Err())
+	}
+
+	rewrite(`{"profiles": [{"name": "p", "midi_device": "fourth"}]}`)
+	if len(applied) != 2 || applied[1] != "fourth" || w.Err() != nil {
+		t.Fatalf("expected the fixed config to be applied and the error cleared, got applied %v, err %v", applied, w.Err())
+	}
+}
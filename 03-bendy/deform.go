@@ -0,0 +1,202 @@
+package main
+
+import "math"
+
+// Deformer moves points in a shape's local space. Deformers can be stacked on
+// a Shape, and are applied in order after its outline is flattened.
+type Deformer interface {
+	Deform(p Vec2D) Vec2D
+}
+
+// Bend curls the line through Origin at angle Axis into an arc, so that points
+// on the line end up Curvature radians further around the arc per pixel.
+// Points off the line keep their distance from it. Negative curvature bends
+// the other way, and zero leaves points unchanged.
+type Bend struct {
+	Origin    Vec2D
+	Axis      float32 // radians
+	Curvature float32 // 1/radius of the arc the axis is bent into
+}
+
+func (b Bend) Deform(p Vec2D) Vec2D {
+	if b.Curvature == 0 {
+		return p
+	}
+	// work in a space where the axis is the x axis through the origin
+	local := p.Sub(b.Origin).Rotate(-b.Axis)
+	radius := 1 / b.Curvature
+	s, c := math.Sincos(float64(local.X * b.Curvature))
+	r := radius - local.Y
+	local = Vec2D{r * float32(s), radius - r*float32(c)}
+	return local.Rotate(b.Axis).Add(b.Origin)
+}
+
+// Twist rotates points around Center by Angle radians, falling off to no
+// rotation at Radius and beyond.
+type Twist struct {
+	Center Vec2D
+	Angle  float32
+	Radius float32
+}
+
+func (t Twist) Deform(p Vec2D) Vec2D {
+	if t.Angle == 0 || t.Radius <= 0 {
+		return p
+	}
+	d := p.Sub(t.Center)
+	falloff := 1 - d.Length()/t.Radius
+	if falloff <= 0 {
+		return p
+	}
+	return d.Rotate(t.Angle * falloff).Add(t.Center)
+}
+
+// Taper scales points towards the line through Origin at angle Axis, by an
+// amount that changes with distance along that line. At Length along the
+// axis, points are scaled by 1+Amount; at the same distance the other way, by
+// 1-Amount (but never below 0).
+type Taper struct {
+	Origin Vec2D
+	Axis   float32 // radians
+	Amount float32
+	Length float32
+}
+
+func (t Taper) Deform(p Vec2D) Vec2D {
+	if t.Amount == 0 || t.Length == 0 {
+		return p
+	}
+	local := p.Sub(t.Origin).Rotate(-t.Axis)
+	f := 1 + t.Amount*local.X/t.Length
+	if f < 0 {
+		f = 0
+	}
+	local.Y *= f
+	return local.Rotate(t.Axis).Add(t.Origin)
+}
+
+// Wave offsets points sideways from angle Axis along a sine wave that runs
+// along the axis.
+type Wave struct {
+	Axis       float32 // radians
+	Amplitude  float32
+	Wavelength float32
+	Phase      float32 // radians
+}
+
+func (w Wave) Deform(p Vec2D) Vec2D {
+	if w.Amplitude == 0 || w.Wavelength == 0 {
+		return p
+	}
+	local := p.Rotate(-w.Axis)
+	local.Y += w.Amplitude * float32(math.Sin(float64(local.X*twoPi/w.Wavelength+w.Phase)))
+	return local.Rotate(w.Axis)
+}
+
+// Lattice is a free-form deformer: a grid of control points covering the
+// rectangle from Min to Max, each of which can be offset to drag nearby
+// points along with it. Points between control points move by a bilinear
+// blend of the surrounding offsets, and points outside the rectangle move
+// with the nearest edge.
+type Lattice struct {
+	Min, Max   Vec2D
+	cols, rows int
+	offsets    []Vec2D
+}
+
+// NewLattice returns a lattice with cols x rows control points (at least 2x2), none of them offset.
+func NewLattice(min, max Vec2D, cols, rows int) *Lattice {
+	if cols < 2 {
+		cols = 2
+	}
+	if rows < 2 {
+		rows = 2
+	}
+	return &Lattice{
+		Min:     min,
+		Max:     max,
+		cols:    cols,
+		rows:    rows,
+		offsets: make([]Vec2D, cols*rows),
+	}
+}
+
+func (l *Lattice) Size() (cols, rows int) {
+	return l.cols, l.rows
+}
+
+func (l *Lattice) Offset(col, row int) Vec2D {
+	return l.offsets[row*l.cols+col]
+}
+
+func (l *Lattice) SetOffset(col, row int, offset Vec2D) {
+	l.offsets[row*l.cols+col] = offset
+}
+
+func (l *Lattice) Deform(p Vec2D) Vec2D {
+	cx, fx := latticeCell(p.X, l.Min.X, l.Max.X, l.cols)
+	cy, fy := latticeCell(p.Y, l.Min.Y, l.Max.Y, l.rows)
+	top := l.Offset(cx, cy).Lerp(l.Offset(cx+1, cy), fx)
+	bottom := l.Offset(cx, cy+1).Lerp(l.Offset(cx+1, cy+1), fx)
+	return p.Add(top.Lerp(bottom, fy))
+}
+
+// latticeCell returns which of the n-1 cells between min and max v falls in,
+// and how far across that cell it is, in the range [0,1].
+func latticeCell(v, min, max float32, n int) (int, float32) {
+	if max == min {
+		return 0, 0
+	}
+	t := (v - min) / (max - min) * float32(n-1)
+	switch {
+	case t <= 0:
+		return 0, 0
+	case t >= float32(n-1):
+		return n - 2, 1
+	}
+	cell := int(t)
+	if cell > n-2 {
+		cell = n - 2
+	}
+	return cell, t - float32(cell)
+}
+
+// knobRange maps a knob value in [0,127] to [min,max].
+func knobRange(value int, min, max float32) float32 {
+	return min + (max-min)*float32(value)/127
+}
+
+// appendDeformed appends points to out, after splitting edges so none are
+// longer than maxEdge and running every point through each deformer in turn.
+// Splitting the edges first lets straight lines curve when deformed.
+func appendDeformed(out, points []Vec2D, closed bool, maxEdge float32, deformers []Deformer) []Vec2D {
+	deform := func(p Vec2D) Vec2D {
+		for _, d := range deformers {
+			p = d.Deform(p)
+		}
+		return p
+	}
+
+	for i, p := range points {
+		out = append(out, deform(p))
+
+		var next Vec2D
+		switch {
+		case i+1 < len(points):
+			next = points[i+1]
+		case closed && len(points) > 2:
+			next = points[0]
+		default:
+			continue
+		}
+		if maxEdge <= 0 {
+			continue
+		}
+
+		steps := int(math.Ceil(float64(p.Distance(next) / maxEdge)))
+		for j := 1; j < steps; j++ {
+			out = append(out, deform(p.Lerp(next, float32(j)/float32(steps))))
+		}
+	}
+	return out
+}
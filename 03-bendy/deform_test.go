@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeformers(t *testing.T) {
+	lattice := NewLattice(Vec2D{0, 0}, Vec2D{10, 10}, 3, 3)
+	lattice.SetOffset(1, 1, Vec2D{2, 0})
+
+	cases := []struct {
+		Name     string
+		Deformer Deformer
+		In       Vec2D
+		Expected Vec2D
+	}{
+		{"bend none", Bend{Curvature: 0}, Vec2D{5, 3}, Vec2D{5, 3}},
+		{"bend origin", Bend{Curvature: 0.1}, Vec2D{0, 0}, Vec2D{0, 0}},
+		{"bend off axis at origin", Bend{Curvature: 0.1}, Vec2D{0, 3}, Vec2D{0, 3}},
+		// radius 10, so a quarter turn is 5π along the axis
+		{"bend quarter turn", Bend{Curvature: 0.1}, Vec2D{5 * math.Pi, 0}, Vec2D{10, 10}},
+		{"bend quarter turn inside", Bend{Curvature: 0.1}, Vec2D{5 * math.Pi, 2}, Vec2D{8, 10}},
+		{"bend other way", Bend{Curvature: -0.1}, Vec2D{5 * math.Pi, 0}, Vec2D{10, -10}},
+		{"bend vertical axis", Bend{Origin: Vec2D{1, 1}, Axis: math.Pi / 2, Curvature: 0.1}, Vec2D{1, 1 + 5*math.Pi}, Vec2D{-9, 11}},
+		{"twist center", Twist{Angle: 1, Radius: 10}, Vec2D{0, 0}, Vec2D{0, 0}},
+		{"twist halfway", Twist{Angle: math.Pi, Radius: 10}, Vec2D{5, 0}, Vec2D{0, 5}},
+		{"twist outside", Twist{Angle: math.Pi, Radius: 10}, Vec2D{11, 0}, Vec2D{11, 0}},
+		{"taper origin", Taper{Amount: 1, Length: 10}, Vec2D{0, 4}, Vec2D{0, 4}},
+		{"taper wide", Taper{Amount: 1, Length: 10}, Vec2D{10, 4}, Vec2D{10, 8}},
+		{"taper narrow", Taper{Amount: 0.5, Length: 10}, Vec2D{-10, 4}, Vec2D{-10, 2}},
+		{"taper pinched", Taper{Amount: 1, Length: 10}, Vec2D{-20, 4}, Vec2D{-20, 0}},
+		{"wave zero", Wave{Amplitude: 2, Wavelength: 8}, Vec2D{4, 1}, Vec2D{4, 1}},
+		{"wave peak", Wave{Amplitude: 2, Wavelength: 8}, Vec2D{2, 1}, Vec2D{2, 3}},
+		{"wave vertical", Wave{Axis: math.Pi / 2, Amplitude: 2, Wavelength: 8}, Vec2D{1, 2}, Vec2D{-1, 2}},
+		{"lattice corner", lattice, Vec2D{0, 0}, Vec2D{0, 0}},
+		{"lattice control point", lattice, Vec2D{5, 5}, Vec2D{7, 5}},
+		{"lattice between", lattice, Vec2D{2.5, 5}, Vec2D{3.5, 5}},
+		{"lattice blend", lattice, Vec2D{2.5, 2.5}, Vec2D{3, 2.5}},
+		{"lattice outside", lattice, Vec2D{5, -5}, Vec2D{5, -5}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assertVec(t, tc.Deformer.Deform(tc.In), tc.Expected)
+		})
+	}
+}
+
+func TestAppendDeformed(t *testing.T) {
+	square := []Vec2D{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	shift := Twist{Center: Vec2D{5, 5}, Angle: 0.5, Radius: 20}
+
+	cases := []struct {
+		Name     string
+		Closed   bool
+		MaxEdge  float32
+		Expected int
+	}{
+		{"closed", true, 5, 8},
+		{"open", false, 5, 7},
+		{"uneven", true, 3, 16},
+		{"no split", true, 0, 4},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			out := appendDeformed(nil, square, tc.Closed, tc.MaxEdge, []Deformer{shift})
+			if len(out) != tc.Expected {
+				t.Fatalf("expected %d points, got %d", tc.Expected, len(out))
+			}
+			assertVec(t, out[0], shift.Deform(square[0]))
+		})
+	}
+
+	// deformers run in order
+	out := appendDeformed(nil, []Vec2D{{1, 1}}, false, 1, []Deformer{
+		Taper{Amount: 1, Length: 1},
+		Wave{Amplitude: 1, Wavelength: 4},
+	})
+	assertVec(t, out[0], Vec2D{1, 3})
+}
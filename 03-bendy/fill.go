@@ -0,0 +1,202 @@
+package main
+
+import (
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// FillRule decides which parts of a shape are inside when its outline
+// crosses over itself, or when it has more than one contour.
+type FillRule int
+
+const (
+	// FillRuleNonZero fills any area that the outline winds around (the SVG default).
+	FillRuleNonZero FillRule = iota
+
+	// FillRuleEvenOdd fills areas that are inside an odd number of loops of the outline.
+	FillRuleEvenOdd
+)
+
+func (r FillRule) inside(winding int) bool {
+	if r == FillRuleEvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// fillEdge is a non-horizontal edge of a contour, stored top to bottom.
+type fillEdge struct {
+	x0, y0, x1, y1 float64
+	dir            int // +1 if the contour goes down along this edge, -1 if it goes up
+}
+
+func (e fillEdge) xAt(y float64) float64 {
+	return e.x0 + (e.x1-e.x0)*(y-e.y0)/(e.y1-e.y0)
+}
+
+// appendFillVerticesAndIndices appends triangles that cover the inside of the
+// closed contours, as decided by rule. Unlike vector.Path's filling, the
+// triangles never overlap, so they can be drawn with FillAll and any color,
+// including translucent colors and gradients.
+//
+// The contours are cut into horizontal bands, at every vertex and every point
+// where two edges cross. Within a band no edges cross, so the band is made of
+// trapezoids between pairs of edges, and each trapezoid is either inside or
+// outside.
+func appendFillVerticesAndIndices(vs []ebiten.Vertex, is []uint32, contours [][]Vec2D, rule FillRule) ([]ebiten.Vertex, []uint32) {
+	var edges []fillEdge
+	var ys []float64
+	for _, c := range contours {
+		if len(c) < 3 {
+			continue
+		}
+		for i := range c {
+			a, b := c[i], c[(i+1)%len(c)]
+			e := fillEdge{float64(a.X), float64(a.Y), float64(b.X), float64(b.Y), 1}
+			if e.y0 == e.y1 {
+				continue
+			}
+			if e.y0 > e.y1 {
+				e = fillEdge{e.x1, e.y1, e.x0, e.y0, -1}
+			}
+			edges = append(edges, e)
+			ys = append(ys, e.y0, e.y1)
+		}
+	}
+
+	for i := range edges {
+		for j := i + 1; j < len(edges); j++ {
+			if y, ok := edgeCrossingY(edges[i], edges[j]); ok {
+				ys = append(ys, y)
+			}
+		}
+	}
+
+	sort.Float64s(ys)
+
+	type crossing struct {
+		xTop, xBot float64
+		dir        int
+	}
+	var active []crossing
+
+	for i := 0; i+1 < len(ys); i++ {
+		top, bot := ys[i], ys[i+1]
+		if bot-top < 1e-6 {
+			continue
+		}
+
+		active = active[:0]
+		for _, e := range edges {
+			if e.y0 <= top && e.y1 >= bot {
+				active = append(active, crossing{e.xAt(top), e.xAt(bot), e.dir})
+			}
+		}
+		sort.Slice(active, func(a, b int) bool {
+			return active[a].xTop+active[a].xBot < active[b].xTop+active[b].xBot
+		})
+
+		winding := 0
+		var left crossing
+		for _, c := range active {
+			wasInside := rule.inside(winding)
+			winding += c.dir
+			isInside := rule.inside(winding)
+			switch {
+			case !wasInside && isInside:
+				left = c
+			case wasInside && !isInside:
+				vs, is = appendQuad(vs, is,
+					Vec2D{float32(left.xTop), float32(top)},
+					Vec2D{float32(c.xTop), float32(top)},
+					Vec2D{float32(c.xBot), float32(bot)},
+					Vec2D{float32(left.xBot), float32(bot)},
+				)
+			}
+		}
+	}
+
+	return vs, is
+}
+
+// edgeCrossingY returns the y value where the two edges cross, if they do so
+// strictly between their endpoints' y values.
+func edgeCrossingY(a, b fillEdge) (float64, bool) {
+	top := math.Max(a.y0, b.y0)
+	bot := math.Min(a.y1, b.y1)
+	if bot <= top {
+		return 0, false
+	}
+	// compare the gap between the edges at the top and bottom of their shared y range
+	dTop := a.xAt(top) - b.xAt(top)
+	dBot := a.xAt(bot) - b.xAt(bot)
+	if dTop == 0 || dBot == 0 || (dTop < 0) == (dBot < 0) {
+		return 0, false
+	}
+	return top + (bot-top)*dTop/(dTop-dBot), true
+}
+
+func appendQuad(vs []ebiten.Vertex, is []uint32, a, b, c, d Vec2D) ([]ebiten.Vertex, []uint32) {
+	base := uint32(len(vs))
+	for _, p := range [4]Vec2D{a, b, c, d} {
+		vs = append(vs, ebiten.Vertex{DstX: p.X, DstY: p.Y, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1})
+	}
+	return vs, append(is, base, base+1, base+2, base, base+2, base+3)
+}
+
+// subdivideTriangles splits the triangles in vs/is (starting at the given
+// index into is) until no edge is longer than maxEdge, so that per-vertex
+// colors can closely follow a gradient. An edge shared by two triangles is
+// split at the same vertex in both, so there are no T-junctions for cracks
+// to show through.
+func subdivideTriangles(vs []ebiten.Vertex, is []uint32, start int, maxEdge float32) ([]ebiten.Vertex, []uint32) {
+	maxSq := maxEdge * maxEdge
+	tris := append([]uint32(nil), is[start:]...)
+	is = is[:start]
+
+	// the vertex at the midpoint of each edge split so far
+	type edge struct{ a, b uint32 } // a < b
+	mids := make(map[edge]uint32)
+
+	for len(tris) > 0 {
+		n := len(tris) - 3
+		t := [3]uint32{tris[n], tris[n+1], tris[n+2]}
+		tris = tris[:n]
+
+		// find the longest edge
+		longest, longestSq := 0, float32(0)
+		for i := 0; i < 3; i++ {
+			a, b := vs[t[i]], vs[t[(i+1)%3]]
+			dx, dy := b.DstX-a.DstX, b.DstY-a.DstY
+			if d := dx*dx + dy*dy; d > longestSq {
+				longest, longestSq = i, d
+			}
+		}
+
+		if longestSq <= maxSq {
+			is = append(is, t[0], t[1], t[2])
+			continue
+		}
+
+		// split the longest edge at its midpoint
+		a, b, c := t[longest], t[(longest+1)%3], t[(longest+2)%3]
+		e := edge{a, b}
+		if e.a > e.b {
+			e.a, e.b = e.b, e.a
+		}
+		m, ok := mids[e]
+		if !ok {
+			mid := vs[a]
+			mid.DstX = (vs[a].DstX + vs[b].DstX) / 2
+			mid.DstY = (vs[a].DstY + vs[b].DstY) / 2
+			m = uint32(len(vs))
+			vs = append(vs, mid)
+			mids[e] = m
+		}
+		tris = append(tris, a, m, c, m, b, c)
+	}
+
+	return vs, is
+}
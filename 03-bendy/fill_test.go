@@ -0,0 +1,151 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func square(x, y, size float32) []Vec2D {
+	return []Vec2D{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}
+}
+
+func reversed(pts []Vec2D) []Vec2D {
+	r := make([]Vec2D, len(pts))
+	for i, p := range pts {
+		r[len(pts)-1-i] = p
+	}
+	return r
+}
+
+// polygonArea uses the shoelace formula
+func polygonArea(pts []Vec2D) float32 {
+	var area float32
+	for i := range pts {
+		area += pts[i].Cross(pts[(i+1)%len(pts)])
+	}
+	return float32(math.Abs(float64(area))) / 2
+}
+
+// meshArea sums the area of each triangle
+func meshArea[I uint16 | uint32](vs []ebiten.Vertex, is []I) float32 {
+	var area float64 // summing lots of small triangles loses too much precision in a float32
+	for i := 0; i+2 < len(is); i += 3 {
+		a, b, c := vs[is[i]], vs[is[i+1]], vs[is[i+2]]
+		ab := Vec2D{b.DstX - a.DstX, b.DstY - a.DstY}
+		ac := Vec2D{c.DstX - a.DstX, c.DstY - a.DstY}
+		area += math.Abs(float64(ab.Cross(ac))) / 2
+	}
+	return float32(area)
+}
+
+func TestAppendFillVerticesAndIndices(t *testing.T) {
+	star := make([]Vec2D, 5)
+	for i := range star {
+		// visit every other point of a pentagon to make a pentagram
+		star[i] = Vec2D{0, -10}.Rotate(float32(i*2) * 2 * math.Pi / 5)
+	}
+	pentagon := make([]Vec2D, 5)
+	for i := range pentagon {
+		pentagon[i] = Vec2D{0, -10}.Rotate(float32(i) * 2 * math.Pi / 5)
+	}
+	// the star's inner vertices (where its edges cross) are on a circle 1/φ² the size of the outer one
+	phi := (1 + math.Sqrt(5)) / 2
+	outline := make([]Vec2D, 10)
+	for i := range outline {
+		r := float32(10)
+		if i%2 == 1 {
+			r /= float32(phi * phi)
+		}
+		outline[i] = Vec2D{0, -r}.Rotate(float32(i) * math.Pi / 5)
+	}
+	pentagonArea := polygonArea(pentagon)
+	innerArea := pentagonArea / float32(phi*phi*phi*phi)
+	starArea := polygonArea(outline)
+
+	cases := []struct {
+		Name     string
+		Contours [][]Vec2D
+		Rule     FillRule
+		Expect   float32
+	}{
+		{"square", [][]Vec2D{square(0, 0, 10)}, FillRuleNonZero, 100},
+		{"square reversed", [][]Vec2D{reversed(square(0, 0, 10))}, FillRuleNonZero, 100},
+		{"triangle", [][]Vec2D{{{0, 0}, {10, 0}, {0, 10}}}, FillRuleNonZero, 50},
+		{"concave L", [][]Vec2D{{{0, 0}, {10, 0}, {10, 5}, {5, 5}, {5, 10}, {0, 10}}}, FillRuleNonZero, 75},
+		{"degenerate", [][]Vec2D{{{0, 0}, {10, 0}}}, FillRuleNonZero, 0},
+		{"overlap nonzero", [][]Vec2D{square(0, 0, 2), square(1, 1, 2)}, FillRuleNonZero, 7},
+		{"overlap evenodd", [][]Vec2D{square(0, 0, 2), square(1, 1, 2)}, FillRuleEvenOdd, 6},
+		{"hole nonzero", [][]Vec2D{square(0, 0, 10), reversed(square(2, 2, 6))}, FillRuleNonZero, 64},
+		{"same direction hole nonzero", [][]Vec2D{square(0, 0, 10), square(2, 2, 6)}, FillRuleNonZero, 100},
+		{"hole evenodd", [][]Vec2D{square(0, 0, 10), square(2, 2, 6)}, FillRuleEvenOdd, 64},
+		{"pentagon", [][]Vec2D{pentagon}, FillRuleNonZero, pentagonArea},
+		{"pentagram nonzero", [][]Vec2D{star}, FillRuleNonZero, starArea},
+		{"pentagram evenodd", [][]Vec2D{star}, FillRuleEvenOdd, starArea - innerArea},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			vs, is := appendFillVerticesAndIndices(nil, nil, tc.Contours, tc.Rule)
+			if len(is)%3 != 0 {
+				t.Fatalf("indices are not a list of triangles: %d", len(is))
+			}
+			area := meshArea(vs, is)
+			if math.Abs(float64(area-tc.Expect)) > 1e-3*math.Max(1, float64(tc.Expect)) {
+				t.Errorf("expected area %v, got %v", tc.Expect, area)
+			}
+		})
+	}
+}
+
+func TestSubdivideTriangles(t *testing.T) {
+	vs, is := appendFillVerticesAndIndices(nil, nil, [][]Vec2D{square(0, 0, 100)}, FillRuleNonZero)
+	vs, is = subdivideTriangles(vs, is, 0, 8)
+
+	assertNear(t, "area", meshArea(vs, is), 10000)
+	for i := 0; i < len(is); i += 3 {
+		for j := 0; j < 3; j++ {
+			a, b := vs[is[i+j]], vs[is[i+(j+1)%3]]
+			if d := (Vec2D{a.DstX, a.DstY}).Distance(Vec2D{b.DstX, b.DstY}); d > 8 {
+				t.Fatalf("edge is longer than 8: %v", d)
+			}
+		}
+	}
+
+	// no T-junctions: inside the square, every edge is shared by exactly two
+	// triangles, and no two vertices are in the same place
+	type edge struct{ a, b uint32 }
+	edges := make(map[edge]int)
+	for i := 0; i < len(is); i += 3 {
+		for j := 0; j < 3; j++ {
+			e := edge{is[i+j], is[i+(j+1)%3]}
+			if e.a > e.b {
+				e.a, e.b = e.b, e.a
+			}
+			edges[e]++
+		}
+	}
+	onSide := func(a, b ebiten.Vertex) bool {
+		return (a.DstX == b.DstX && (a.DstX == 0 || a.DstX == 100)) || (a.DstY == b.DstY && (a.DstY == 0 || a.DstY == 100))
+	}
+	for e, n := range edges {
+		a, b := vs[e.a], vs[e.b]
+		expected := 2
+		if onSide(a, b) {
+			expected = 1
+		}
+		if n != expected {
+			t.Fatalf("edge (%v,%v)-(%v,%v) is used by %d triangles", a.DstX, a.DstY, b.DstX, b.DstY, n)
+		}
+	}
+	used := make(map[uint32]bool)
+	points := make(map[Vec2D]bool)
+	for _, i := range is {
+		used[i] = true
+		points[Vec2D{vs[i].DstX, vs[i].DstY}] = true
+	}
+	if len(used) != len(points) {
+		t.Errorf("expected one vertex per point, got %d vertices for %d points", len(used), len(points))
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+type Flags struct {
+	ConfigPath   string
+	Profile      string
+	Device       string
+	Reconfigure  bool
+	ListDevices  bool
+	WindowWidth  int
+	WindowHeight int
+	Fullscreen   bool
+	DialPath     string
+	FontPath     string
+	Theme        string
+	NoAudio      bool
+
+	ScreenshotPath string
+	Ticks          int
+	Knobs          string
+	Goal           int
+}
+
+func ParseFlags() Flags {
+	var f Flags
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s friday-config -h (for help creating a profile without the game)\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&f.ConfigPath, "config", DefaultConfigPath(), "path to the config file")
+	flag.StringVar(&f.Profile, "profile", "", "name of the config profile to use (default: the first profile that matches an available MIDI device)")
+	flag.StringVar(&f.Device, "device", "", "MIDI device to use (overrides the selected profile)")
+	flag.BoolVar(&f.Reconfigure, "reconfigure", false, "run the setup wizard, even if a config file already exists")
+	flag.BoolVar(&f.ListDevices, "list-devices", false, "list available MIDI devices and exit")
+	flag.IntVar(&f.WindowWidth, "width", screenWidth, "window width")
+	flag.IntVar(&f.WindowHeight, "height", screenHeight, "window height")
+	flag.BoolVar(&f.Fullscreen, "fullscreen", false, "start in fullscreen mode")
+	flag.StringVar(&f.DialPath, "dial", "", "SVG file to draw the dial from (default: the built-in dial)")
+	flag.StringVar(&f.FontPath, "font", "", "TTF or OTF file to draw text with (default: the built-in font)")
+	flag.StringVar(&f.Theme, "theme", "", "name of the color theme to use (default: the config's theme, or \"default\")")
+	flag.BoolVar(&f.NoAudio, "no-audio", false, "don't play sounds (for running without an audio device)")
+	flag.StringVar(&f.ScreenshotPath, "screenshot", "", "render to this PNG file and exit, without a config file or MIDI device")
+	flag.IntVar(&f.Ticks, "ticks", 60, "with -screenshot, how many updates to run before rendering")
+	flag.StringVar(&f.Knobs, "knobs", "0,0,0,0", "with -screenshot, comma separated values (0-127) to set the knobs to")
+	flag.IntVar(&f.Goal, "goal", -1, "with -screenshot, the knob 0 value that makes the dial click (default: random)")
+	flag.Parse()
+	return f
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// Font is a TrueType or OpenType font, which can be drawn at any size.
+type Font struct {
+	font  *opentype.Font
+	faces map[float64]font.Face // by size
+}
+
+var (
+	defaultFont     *Font
+	defaultFontOnce sync.Once
+)
+
+// DefaultFont returns the built-in font (Go Regular), which is used when no
+// other font is given.
+func DefaultFont() *Font {
+	defaultFontOnce.Do(func() {
+		f, err := ParseFont(goregular.TTF)
+		if err != nil {
+			panic(err)
+		}
+		defaultFont = f
+	})
+	return defaultFont
+}
+
+// LoadFont loads a .ttf or .otf file.
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadFont(%s): %w", path, err)
+	}
+	f, err := ParseFont(data)
+	if err != nil {
+		return nil, fmt.Errorf("LoadFont(%s): %w", path, err)
+	}
+	return f, nil
+}
+
+// ParseFont parses the contents of a .ttf or .otf file.
+func ParseFont(data []byte) (*Font, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("ParseFont: %w", err)
+	}
+	return &Font{font: f, faces: make(map[float64]font.Face)}, nil
+}
+
+// Face returns a face for drawing the font size pixels tall. Faces are kept,
+// so asking for the same size again is cheap, and reuses the glyphs ebiten
+// has already rendered for it.
+func (f *Font) Face(size float64) font.Face {
+	if face, ok := f.faces[size]; ok {
+		return face
+	}
+	// at 72 DPI, a point is a pixel
+	face, err := opentype.NewFace(f.font, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		// only possible with bad options, and these are always fine
+		panic(fmt.Errorf("Font.Face(%v): %w", size, err))
+	}
+	f.faces[size] = face
+	return face
+}
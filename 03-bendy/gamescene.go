@@ -6,37 +6,253 @@ import (
 	"math/rand"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
-var shapeSrc []Vec2D = []Vec2D{
-	{X: 0, Y: 15},
-	{X: 2, Y: 20},
-	{X: 9, Y: 20},
-	{X: 20, Y: 9},
-	{X: 20, Y: -9},
-	{X: 9, Y: -20},
-	{X: -9, Y: -20},
-	{X: -20, Y: -9},
-	{X: -20, Y: 9},
-	{X: -9, Y: 20},
-	{X: -2, Y: 20},
+// newDialPath returns a circle with a notch cut into it, to show which way the dial is turned.
+func newDialPath() *Path {
+	notchY := float32(math.Sqrt(dialRadius*dialRadius - 2*2))
+
+	var p Path
+	p.MoveTo(Vec2D{X: 0, Y: 15})
+	p.LineTo(Vec2D{X: 2, Y: notchY})
+	p.ArcTo(dialRadius, dialRadius, 0, true, false, Vec2D{X: -2, Y: notchY})
+	p.Close()
+	return &p
 }
 
 type GameScene struct {
-	midiMgr *MidiMgr
-	center  Vec2D
-	rot     int // [0,127]
-	shape   []Vec2D
-	rotGoal int // [0,127]
+	midiMgr    *MidiMgr
+	cfgWatcher *ConfigWatcher
+	rot        int   // [0,127]
+	dial       *Node // rotated by knob 0
+	dialParts  []dialPart
+	rotGoal    int // [0,127]
+	renderer   *Renderer
+	font       *Font
+	tweens     Tweens
+
+	rotTarget float32 // where the dial is turning to, in radians
+	rotTween  *Tween
+
+	themes     []Theme // cycled through with the T key
+	themeIndex int
+	themeName  string  // the theme asked for by the last SetThemes
+	fadeFrom   Theme   // colors when the last theme switch started
+	fade       float32 // [0,1] from fadeFrom to the current theme
+	fadeTween  *Tween
+
+	clicked   bool    // whether rot was at rotGoal as of the last update
+	tint      float32 // [0,1] how far the dial is tinted toward the theme's success color
+	tintTween *Tween
+	sparks    *Emitter // burst from the rim of the dial when it clicks
+
+	sounds SoundPlayer
+	ticks  int // tick sounds still to play, one per update
+}
+
+// dialPart is a shape in the dial, with deformers that are centered on the dial.
+type dialPart struct {
+	shape *Shape
+	color color.Color // stroke color, or nil to use the theme's foreground color
+	fill  Paint       // before tinting
+	unit  float32     // size of one unit of the built-in dial, in the shape's local space
+	bend  *Bend
+	twist *Twist
+	wave  *Wave
+}
+
+// newDialPart adds deformers to shape, centered on center (in the shape's local space).
+func newDialPart(shape *Shape, color color.Color, center Vec2D, unit float32) dialPart {
+	part := dialPart{
+		shape: shape,
+		color: color,
+		fill:  shape.Fill,
+		unit:  unit,
+		bend:  &Bend{Origin: center},
+		twist: &Twist{Center: center, Radius: 24 * unit},
+		wave:  &Wave{Axis: math.Pi / 2, Wavelength: 10 * unit},
+	}
+	shape.Deformers = []Deformer{part.bend, part.twist, part.wave}
+	return part
 }
 
-func NewGameScene(midiMgr *MidiMgr) *GameScene {
+// setDeform updates the part's deformers from the given knob values, and
+// only invalidates its shape if one of them changed.
+func (p dialPart) setDeform(bend, twist, wave int) {
+	curvature := knobRange(bend, 0, 0.08) / p.unit
+	angle := knobRange(twist, 0, math.Pi)
+	amplitude := knobRange(wave, 0, 4) * p.unit
+	if curvature == p.bend.Curvature && angle == p.twist.Angle && amplitude == p.wave.Amplitude {
+		return
+	}
+	p.bend.Curvature = curvature
+	p.twist.Angle = angle
+	p.wave.Amplitude = amplitude
+	p.shape.Invalidate()
+}
+
+// setColors tints the part's colors toward tint, by t. Changing colors doesn't
+// invalidate the shape, as they're applied when it's drawn.
+func (p dialPart) setColors(fg, tint color.Color, t float32) {
+	stroke := p.color
+	if stroke == nil {
+		stroke = fg
+	}
+	p.shape.Color = LerpColor(stroke, tint, t)
+	if fill, ok := p.fill.(SolidPaint); ok && fill.Color != nil {
+		p.shape.Fill = SolidPaint{LerpColor(fill.Color, tint, t)}
+	}
+}
+
+// NewGameScene returns the game, with a dial drawn from dialSVG, or the
+// built-in dial if dialSVG is nil. Text is drawn in fnt, or the default font if nil.
+func NewGameScene(midiMgr *MidiMgr, cfgWatcher *ConfigWatcher, dialSVG *SVGImage, fnt *Font) *GameScene {
+	dial := NewNode()
+	dial.Xfm.SetPos(Vec2D{X: screenWidth / 2, Y: screenHeight / 2})
+
+	var parts []dialPart
+	if dialSVG == nil {
+		dial.Xfm.SetUniformScale(scale)
+		shape := NewPathShape(newDialPath(), nil, 1)
+		dial.AddChild(&shape.Node)
+		parts = append(parts, newDialPart(shape, nil, Vec2D{}, 1))
+	} else {
+		// fit the image to the size of the built-in dial, and spin it around its center
+		size := float32(math.Max(float64(dialSVG.Width), float64(dialSVG.Height)))
+		if size <= 0 {
+			size = 2 * dialRadius
+		}
+		center := Vec2D{X: dialSVG.Width / 2, Y: dialSVG.Height / 2}
+		dial.Xfm.SetPivot(center)
+		dial.Xfm.SetUniformScale(2 * dialRadius * scale / size)
+
+		for _, shape := range dialSVG.Shapes {
+			dial.AddChild(&shape.Node)
+			m := shape.Xfm.Matrix()
+			localCenter := center
+			if inv, err := m.Inverse(); err == nil {
+				localCenter = inv.MultiplyVec2D(center)
+			}
+			unit := size / (2 * dialRadius)
+			if det := m.Determinant(); det != 0 {
+				unit /= float32(math.Sqrt(math.Abs(float64(det))))
+			}
+			parts = append(parts, newDialPart(shape, shape.Color, localCenter, unit))
+		}
+	}
+
+	sparks := NewEmitter()
+	sparks.Pos = dial.Xfm.Pos()
+	sparks.Radius = dialRadius * scale
+	sparks.Spread = math.Pi
+	sparks.Speed = 2.5
+	sparks.SpeedSpread = 1.5
+	sparks.Drag = 0.05
+	sparks.Gravity = Vec2D{Y: 0.04}
+	sparks.Life = 40
+	sparks.LifeSpread = 15
+	sparks.Size = 4
+	sparks.EndSize = 1
+
+	// start with the dial where the knob already is, so the first update
+	// doesn't turn it (or play a tick)
+	midiMgr.Update()
+	rot := midiMgr.Knob(0)
+	rotTarget := float32(rot) * twoPi / 127.0
+	dial.Xfm.SetRot(rotTarget)
+
 	return &GameScene{
-		midiMgr: midiMgr,
-		center:  Vec2D{X: screenWidth / 2, Y: screenHeight / 2},
-		rotGoal: rand.Intn(128),
+		midiMgr:    midiMgr,
+		cfgWatcher: cfgWatcher,
+		rot:        rot,
+		dial:       dial,
+		dialParts:  parts,
+		rotGoal:    rand.Intn(128),
+		renderer:   NewRenderer(),
+		font:       fnt,
+		rotTarget:  rotTarget,
+		themes:     BuiltinThemes(),
+		fade:       1,
+		sparks:     sparks,
+		sounds:     NullSounds{},
+	}
+}
+
+// SetThemes replaces the themes that the T key cycles through, and switches
+// straight to the one with the given name (or the first, if it isn't found).
+func (g *GameScene) SetThemes(themes []Theme, name string) {
+	g.themes = themes
+	g.themeName = name
+	g.themeIndex = 0
+	if i := findTheme(themes, name); i >= 0 {
+		g.themeIndex = i
 	}
+	g.fadeTween.Stop()
+	g.fade = 1
+}
+
+// ReloadThemes is SetThemes for a reloaded config. It only switches to the
+// theme with the given name if that's a different theme than was asked for
+// before, or if themes were added, removed or reordered. Otherwise it stays
+// on the current theme (which may have been picked with the T key), so that
+// editing the config doesn't undo that choice.
+func (g *GameScene) ReloadThemes(themes []Theme, name string) {
+	i := -1
+	if g.themeIndex < len(g.themes) {
+		i = findTheme(themes, g.themes[g.themeIndex].Name)
+	}
+	if name != g.themeName || i < 0 || !sameThemeNames(themes, g.themes) {
+		g.SetThemes(themes, name)
+		return
+	}
+	// any fade carries on, but to the reloaded colors
+	g.themes = themes
+	g.themeIndex = i
+}
+
+// sameThemeNames returns whether a and b have the same themes, in the same
+// order, going by name alone.
+func sameThemeNames(a, b []Theme) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// SetSounds sets what plays the scene's sound effects.
+func (g *GameScene) SetSounds(sounds SoundPlayer) {
+	g.sounds = sounds
+}
+
+// NextTheme fades to the next theme, or back to the first after the last.
+func (g *GameScene) NextTheme() {
+	if len(g.themes) == 0 {
+		return
+	}
+	g.fadeFrom = g.Theme()
+	g.fade = 0
+	g.themeIndex = (g.themeIndex + 1) % len(g.themes)
+	g.fadeTween.Stop()
+	g.fadeTween = g.tweens.Add(TweenFloat(&g.fade, 1, themeFadeTicks, Linear))
+}
+
+// Theme returns the colors to draw with, which are part way between two
+// themes while fading from one to the other.
+func (g *GameScene) Theme() Theme {
+	th := DefaultTheme()
+	if g.themeIndex < len(g.themes) {
+		th = g.themes[g.themeIndex]
+	}
+	if g.fade < 1 {
+		th = g.fadeFrom.Lerp(th, g.fade)
+	}
+	return th
 }
 
 const (
@@ -46,15 +262,68 @@ const (
 	minRot     = twoPi / (sliceCount * 2) // anything under this rounds down to 0 (aka 2π)
 	maxRot     = twoPi - minRot           // anything over this rounds up to 2π (aka 0)
 
-	scale = 3
+	scale      = 3
+	dialRadius = 20 // of the built-in dial, before scaling
+	margin     = 10 // between text and the edges of the screen
+
+	// how many updates it takes to...
+	rotTicks       = 6  // turn the dial to a new knob value
+	themeFadeTicks = 15 // fade from one theme to the next
+	tintTicks      = 10 // tint the dial when it clicks, or untint it when it's turned away
+
+	sparkCount = 64 // how many sparks fly off the dial when it clicks
+	maxTicks   = 8  // most tick sounds left to play, so a big jump of the knob doesn't rattle on
 )
 
 func (g *GameScene) Update(mgr *SceneMgr) error {
+	if g.cfgWatcher != nil {
+		g.cfgWatcher.Update()
+	}
 	g.midiMgr.Update()
 
 	prevRot := g.rot
 	g.rot = g.midiMgr.Knob(0)
 
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		g.NextTheme()
+	}
+
+	// ease the dial's tint in and out, rather than snapping it on the click
+	if clicked := g.rot == g.rotGoal; clicked != g.clicked {
+		g.clicked = clicked
+		var tint float32
+		if clicked {
+			tint = 1
+		}
+		g.tintTween.Stop()
+		g.tintTween = g.tweens.Add(TweenFloat(&g.tint, tint, tintTicks, EaseOutQuad))
+		if clicked {
+			g.burstSparks()
+		}
+	}
+	if g.rot != prevRot {
+		if g.clicked {
+			g.sounds.Play(SoundClick)
+			g.ticks = 0
+		} else {
+			// a tick for each step the knob turned, played one per update so
+			// they rattle rather than all landing at once
+			step := g.rot - prevRot
+			if step < 0 {
+				step = -step
+			}
+			g.ticks += step
+			if g.ticks > maxTicks {
+				g.ticks = maxTicks
+			}
+		}
+	}
+	if g.ticks > 0 {
+		g.sounds.Play(SoundTick)
+		g.ticks--
+	}
+	g.sparks.Update()
+
 	// if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
 	// 	g.rot -= sliceRad
 	// 	if g.rot < -minRot {
@@ -68,36 +337,62 @@ func (g *GameScene) Update(mgr *SceneMgr) error {
 	// 	}
 	// }
 
-	if g.rot != prevRot || len(g.shape) == 0 {
-		// regenerate vertices from shape
-		g.shape = make([]Vec2D, len(shapeSrc))
-		rads := float64(g.rot) * twoPi / 127.0
-		cosRot := float32(math.Cos(rads))
-		sinRot := float32(math.Sin(rads))
-		for i, v := range shapeSrc {
-			g.shape[i] = Vec2D{
-				X: scale*(v.X*cosRot-v.Y*sinRot) + g.center.X,
-				Y: scale*(v.X*sinRot+v.Y*cosRot) + g.center.Y,
-			}
-		}
+	// turn smoothly to each new knob value, rather than stepping between them
+	rads := float32(g.rot) * twoPi / 127.0
+	if rads != g.rotTarget {
+		g.rotTarget = rads
+		g.rotTween.Stop()
+		g.rotTween = g.tweens.Add(TweenRot(&g.dial.Xfm, rads, rotTicks, EaseOutCubic))
+	}
+	g.tweens.Update()
+
+	// knobs 1-3 deform the dial, and leave it undeformed when turned all the way down
+	for _, part := range g.dialParts {
+		part.setDeform(g.midiMgr.Knob(1), g.midiMgr.Knob(2), g.midiMgr.Knob(3))
 	}
 
 	return nil
 }
 
-var (
-	colorBG = color.RGBA{0x56, 0x55, 0x54, 0xff}
-	colorFG = color.RGBA{0xf6, 0xf1, 0x93, 0xee}
-)
+// burstSparks sends a ring of sparks flying off the dial, in the theme's
+// colors, fading out as they go.
+func (g *GameScene) burstSparks() {
+	th := g.Theme()
+	g.sparks.Colors = []GradientStop{
+		{Offset: 0, Color: th.Highlight},
+		{Offset: 0.3, Color: th.Success},
+		{Offset: 1, Color: color.Transparent},
+	}
+	g.sparks.Burst(sparkCount)
+}
 
 func (g *GameScene) Draw(mgr *SceneMgr, screen *ebiten.Image) {
-	screen.Fill(colorBG)
-	drawShape(screen, g.shape, 1, colorFG)
+	th := g.Theme()
+	screen.Fill(th.Background)
+	for _, part := range g.dialParts {
+		part.setColors(th.Foreground, th.Success, g.tint)
+		g.renderer.DrawShape(part.shape)
+	}
+	g.sparks.Draw(g.renderer)
+	g.renderer.Flush(screen)
 
 	// msg := fmt.Sprintf("TPS: %0.2f\nRot: %.3f", ebiten.ActualTPS(), g.rot)
-	msg := "Spin the dial with left and right arrows"
+	hud := TextStyle{Font: g.font, Color: th.Foreground}
+	DrawText(screen, "Spin the dial with left and right arrows\nPress T to change theme", Vec2D{X: margin, Y: margin}, hud)
+
 	if g.rot == g.rotGoal {
-		msg += "\n\nCLICK!"
+		click := hud
+		click.Color = th.Highlight
+		click.Size = 48
+		click.Anchor = AnchorTop
+		DrawText(screen, "CLICK!", Vec2D{X: screenWidth / 2, Y: screenHeight/2 + dialRadius*scale + margin}, click)
+	}
+
+	if g.cfgWatcher != nil && g.cfgWatcher.Err() != nil {
+		errStyle := hud
+		errStyle.Color = th.Error
+		errStyle.Anchor = AnchorBottomLeft
+		errStyle.Width = screenWidth - 2*margin
+		DrawText(screen, "Config error: "+g.cfgWatcher.Err().Error(), Vec2D{X: margin, Y: screenHeight - margin}, errStyle)
 	}
-	ebitenutil.DebugPrint(screen, msg)
 }
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeSounds records the sounds played, instead of playing them.
+type fakeSounds struct {
+	played []Sound
+}
+
+func (f *fakeSounds) Play(s Sound) {
+	f.played = append(f.played, s)
+}
+
+func TestGameScene_Sounds(t *testing.T) {
+	const goal = 100
+	ticks := func(n int) []Sound {
+		s := make([]Sound, n)
+		for i := range s {
+			s[i] = SoundTick
+		}
+		return s
+	}
+
+	cases := []struct {
+		Name     string
+		Knob     int   // where knob 0 starts
+		Turns    []int // where knob 0 is turned to, one per update
+		Updates  int   // to run after the turns
+		Expected []Sound
+	}{
+		{"nothing on the first update", 40, nil, 1, nil},
+		{"nothing while still", 40, []int{40, 40}, 5, nil},
+		{"one step", 40, []int{41}, 5, ticks(1)},
+		{"a tick per step", 40, []int{43}, 5, ticks(3)},
+		{"a tick per step, both ways", 40, []int{42, 39}, 10, ticks(5)},
+		{"big turns are capped", 0, []int{127}, 2 * maxTicks, ticks(maxTicks)},
+		{"onto the goal clicks", goal - 1, []int{goal}, 5, []Sound{SoundClick}},
+		{"the click cuts ticks short", goal - 5, []int{goal - 4, goal}, 5, []Sound{SoundTick, SoundClick}},
+		{"off the goal ticks", goal, []int{goal + 2}, 5, ticks(2)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			midiMgr := NewFixedMidiMgr([KNOB_COUNT]int{tc.Knob})
+			scene := NewGameScene(midiMgr, nil, nil, nil)
+			scene.rotGoal = goal
+			sounds := &fakeSounds{}
+			scene.SetSounds(sounds)
+
+			for _, knob := range tc.Turns {
+				midiMgr.shared.knob[0].Store(int32(knob))
+				if err := scene.Update(nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i := 0; i < tc.Updates; i++ {
+				if err := scene.Update(nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if !reflect.DeepEqual(sounds.played, tc.Expected) {
+				t.Errorf("expected %v, got %v", tc.Expected, sounds.played)
+			}
+		})
+	}
+}
+
+func TestNewGameScene_StartsAtKnob(t *testing.T) {
+	scene := NewGameScene(NewFixedMidiMgr([KNOB_COUNT]int{64}), nil, nil, nil)
+	expected := float32(64) * twoPi / 127
+	assertNear(t, "rot", scene.dial.Xfm.Rot(), expected)
+	if err := scene.Update(nil); err != nil {
+		t.Fatal(err)
+	}
+	// already there, so no tween was started
+	assertNear(t, "rot after update", scene.dial.Xfm.Rot(), expected)
+	if scene.rotTween != nil {
+		t.Errorf("expected the dial not to start turning")
+	}
+}
+
+func TestGameScene_TurnsTheWayTheKnobDoes(t *testing.T) {
+	cases := []struct {
+		Name     string
+		From, To int
+	}{
+		{"small turn up", 10, 20},
+		{"small turn down", 20, 10},
+		{"more than half a turn up", 0, 100},
+		{"more than half a turn down", 120, 5},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			midiMgr := NewFixedMidiMgr([KNOB_COUNT]int{tc.From})
+			scene := NewGameScene(midiMgr, nil, nil, nil)
+			midiMgr.shared.knob[0].Store(int32(tc.To))
+
+			prev := scene.dial.Xfm.Rot()
+			for i := 0; i < rotTicks; i++ {
+				if err := scene.Update(nil); err != nil {
+					t.Fatal(err)
+				}
+				rot := scene.dial.Xfm.Rot()
+				if (tc.To > tc.From) != (rot > prev) {
+					t.Fatalf("update %d: expected the dial to turn the same way as the knob, but it went from %v to %v", i, prev, rot)
+				}
+				prev = rot
+			}
+			assertNear(t, "rot", prev, float32(tc.To)*twoPi/127)
+		})
+	}
+}
+
+func TestGameScene_ReloadThemes(t *testing.T) {
+	dark := Theme{Name: "dark", Background: ThemeColor{0x10, 0x10, 0x10, 0xff}}
+	darker := dark
+	darker.Background = ThemeColor{0, 0, 0, 0xff}
+	themes := append(BuiltinThemes(), dark)
+
+	cases := []struct {
+		Name     string
+		Themes   []Theme
+		Select   string
+		Expected Theme
+	}{
+		{"unchanged keeps the picked theme", themes, "default", themes[1]},
+		{"edited colors keep the picked theme", append(BuiltinThemes(), darker), "default", themes[1]},
+		{"new selection switches", themes, "dark", dark},
+		{"added theme switches", append(append([]Theme{}, themes...), Theme{Name: "neon"}), "default", themes[0]},
+		{"removed theme switches", BuiltinThemes(), "default", themes[0]},
+		{"reordered themes switch", []Theme{themes[1], themes[0], dark}, "default", themes[0]},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			scene := NewGameScene(NewFixedMidiMgr([KNOB_COUNT]int{}), nil, nil, nil)
+			scene.SetThemes(themes, "default")
+			scene.NextTheme() // as if T was pressed, to pick "light"
+			scene.tweens.Update()
+
+			scene.ReloadThemes(tc.Themes, tc.Select)
+			for i := 0; i < themeFadeTicks; i++ {
+				scene.tweens.Update()
+			}
+			if th := scene.Theme(); th != tc.Expected {
+				t.Errorf("expected %+v, got %+v", tc.Expected, th)
+			}
+		})
+	}
+}
+
+func TestGameScene_ReloadThemesPicksUpColors(t *testing.T) {
+	dark := Theme{Name: "dark", Background: ThemeColor{0x10, 0x10, 0x10, 0xff}}
+	scene := NewGameScene(NewFixedMidiMgr([KNOB_COUNT]int{}), nil, nil, nil)
+	scene.SetThemes(append(BuiltinThemes(), dark), "default")
+	scene.NextTheme()
+	scene.NextTheme() // on to "dark"
+
+	darker := dark
+	darker.Background = ThemeColor{0, 0, 0, 0xff}
+	scene.ReloadThemes(append(BuiltinThemes(), darker), "default")
+	for i := 0; i < themeFadeTicks; i++ {
+		scene.tweens.Update()
+	}
+	if th := scene.Theme(); th != darker {
+		t.Errorf("expected the reloaded colors %+v, got %+v", darker, th)
+	}
+}
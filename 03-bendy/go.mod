@@ -5,15 +5,17 @@ go 1.20
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.5.5
 	gitlab.com/gomidi/midi/v2 v2.0.30
+	golang.org/x/image v0.18.0
 )
 
 require (
 	github.com/ebitengine/purego v0.3.0 // indirect
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b // indirect
+	github.com/hajimehoshi/oto/v2 v2.4.0 // indirect
 	github.com/jezek/xgb v1.1.0 // indirect
 	golang.org/x/exp v0.0.0-20190731235908-ec7cb31e5a56 // indirect
-	golang.org/x/image v0.6.0 // indirect
 	golang.org/x/mobile v0.0.0-20230301163155-e0f57694e12c // indirect
-	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 )
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// SavePNG writes img to a PNG file at path.
+func SavePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("SavePNG(%s): %w", path, err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("SavePNG(%s): %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("SavePNG(%s): %w", path, err)
+	}
+	return nil
+}
+
+// LoadPNG reads the PNG file at path.
+func LoadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadPNG(%s): %w", path, err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("LoadPNG(%s): %w", path, err)
+	}
+	return img, nil
+}
+
+// ImageDiff describes how two images of the same size differ.
+type ImageDiff struct {
+	Pixels   int   // number of pixels that differ by more than the tolerance
+	MaxDelta uint8 // largest difference in any channel of any pixel
+}
+
+// CompareImages compares got with want, pixel by pixel. A pixel only counts
+// as different if one of its (8-bit, premultiplied) channels differs by more
+// than tolerance, which allows for small differences between GPUs and drivers
+// (particularly in antialiasing).
+func CompareImages(got, want image.Image, tolerance uint8) (ImageDiff, error) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return ImageDiff{}, fmt.Errorf("image is %dx%d, expected %dx%d", gb.Dx(), gb.Dy(), wb.Dx(), wb.Dy())
+	}
+
+	var diff ImageDiff
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			g := color.RGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.RGBA)
+			w := color.RGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.RGBA)
+			d := maxDelta(g, w)
+			if d > diff.MaxDelta {
+				diff.MaxDelta = d
+			}
+			if d > tolerance {
+				diff.Pixels++
+			}
+		}
+	}
+	return diff, nil
+}
+
+// maxDelta returns the largest difference between the channels of a and b.
+func maxDelta(a, b color.RGBA) uint8 {
+	var d uint8
+	for _, c := range [4][2]uint8{{a.R, b.R}, {a.G, b.G}, {a.B, b.B}, {a.A, b.A}} {
+		delta := c[0] - c[1]
+		if c[1] > c[0] {
+			delta = c[1] - c[0]
+		}
+		if delta > d {
+			d = delta
+		}
+	}
+	return d
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "write golden images to testdata/golden, instead of comparing against them")
+
+const (
+	// goldenTolerance is how much a channel of a pixel may differ from the golden image.
+	goldenTolerance = 16
+
+	// goldenMaxPixels is the fraction of pixels that may differ by more than goldenTolerance.
+	goldenMaxPixels = 0.001
+)
+
+// assertGolden compares img against testdata/golden/<name>.png. With -update,
+// it writes img there instead. On failure, img is saved to a temp dir so it
+// can be looked at.
+func assertGolden(t *testing.T, name string, img image.Image) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".png")
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := SavePNG(path, img); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := LoadPNG(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("no golden image for %s (run make golden-update to create it, then look it over before committing it)", name)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := CompareImages(img, want, goldenTolerance)
+	if err == nil {
+		b := img.Bounds()
+		if float64(diff.Pixels) <= goldenMaxPixels*float64(b.Dx()*b.Dy()) {
+			return
+		}
+		err = errors.New("images differ")
+	}
+	failed := filepath.Join(os.TempDir(), name+".png")
+	if saveErr := SavePNG(failed, img); saveErr != nil {
+		t.Logf("couldn't save the failed render: %v", saveErr)
+	}
+	t.Errorf("%s: %v (%d pixels differ, by up to %d); got %s", path, err, diff.Pixels, diff.MaxDelta, failed)
+}
+
+// filledImage returns a w by h image filled with c.
+func filledImage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestCompareImages(t *testing.T) {
+	gray := filledImage(4, 3, color.RGBA{100, 100, 100, 255})
+
+	near := filledImage(4, 3, color.RGBA{100, 100, 100, 255})
+	near.SetRGBA(1, 1, color.RGBA{104, 100, 97, 255})
+
+	far := filledImage(4, 3, color.RGBA{100, 100, 100, 255})
+	far.SetRGBA(0, 0, color.RGBA{100, 100, 100, 200})
+	far.SetRGBA(3, 2, color.RGBA{0, 100, 100, 255})
+
+	// the same pixels, with different bounds
+	offset := filledImage(4, 3, color.RGBA{100, 100, 100, 255})
+	offset.Rect = image.Rect(10, 10, 14, 13)
+
+	cases := []struct {
+		Name   string
+		Got    image.Image
+		Expect ImageDiff
+	}{
+		{"same", gray, ImageDiff{}},
+		{"within tolerance", near, ImageDiff{Pixels: 0, MaxDelta: 4}},
+		{"beyond tolerance", far, ImageDiff{Pixels: 2, MaxDelta: 100}},
+		{"offset", offset, ImageDiff{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			diff, err := CompareImages(tc.Got, gray, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff != tc.Expect {
+				t.Errorf("expected %+v, got %+v", tc.Expect, diff)
+			}
+		})
+	}
+
+	if _, err := CompareImages(filledImage(3, 4, color.RGBA{}), gray, 8); err == nil {
+		t.Errorf("expected an error comparing different sizes")
+	}
+}
+
+func TestSavePNG(t *testing.T) {
+	img := filledImage(4, 3, color.RGBA{10, 20, 30, 255})
+	img.SetRGBA(2, 1, color.RGBA{0, 0, 0, 0})
+
+	path := filepath.Join(t.TempDir(), "img.png")
+	if err := SavePNG(path, img); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPNG(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := CompareImages(loaded, img, 0); err != nil || diff.Pixels != 0 {
+		t.Errorf("expected the same image back, got %+v (%v)", diff, err)
+	}
+}
@@ -1,20 +1,19 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"image"
-	"image/color"
+	"io"
 	"log"
 	"os"
-	"strconv"
-	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/danbrakeley/friday/03-bendy/prompt"
 
 	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
 	_ "gitlab.com/gomidi/midi/v2/drivers/rtmididrv" // autoregisters driver
 )
 
@@ -38,156 +37,264 @@ func init() {
 	whiteImage.WritePixels(pix)
 }
 
-func drawVerticesForUtil(dst *ebiten.Image, vs []ebiten.Vertex, is []uint16, clr color.Color) {
-	r, g, b, a := clr.RGBA()
-	for i := range vs {
-		vs[i].SrcX = 1
-		vs[i].SrcY = 1
-		vs[i].ColorR = float32(r) / 0xffff
-		vs[i].ColorG = float32(g) / 0xffff
-		vs[i].ColorB = float32(b) / 0xffff
-		vs[i].ColorA = float32(a) / 0xffff
-	}
-
-	op := &ebiten.DrawTrianglesOptions{}
-	op.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
-	op.AntiAlias = true
-	dst.DrawTriangles(vs, is, whiteSubImage, op)
-}
+func main() {
+	defer midi.CloseDriver()
 
-func drawShape(dst *ebiten.Image, shape []Vec2D, strokeWidth float32, clr color.Color) {
-	if len(shape) == 0 {
+	if len(os.Args) > 1 && os.Args[1] == "friday-config" {
+		if err := RunConfigCmd(os.Args[2:]); err != nil {
+			log.Fatalf("friday-config: %v", err)
+		}
 		return
 	}
 
-	var path vector.Path
+	flags := ParseFlags()
 
-	end := len(shape) - 1
-	path.MoveTo(shape[end].X, shape[end].Y)
-	for _, v := range shape {
-		path.LineTo(v.X, v.Y)
+	if flags.ListDevices {
+		fmt.Println("MIDI inputs:")
+		printInPorts(os.Stdout, midi.GetInPorts())
+		fmt.Println("MIDI outputs (for midi_out in the config file):")
+		printOutPorts(os.Stdout, midi.GetOutPorts())
+		return
 	}
 
-	strokeOp := &vector.StrokeOptions{}
-	strokeOp.Width = strokeWidth
-	vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, strokeOp)
-
-	drawVerticesForUtil(dst, vs, is, clr)
-}
-
-func main() {
-	defer midi.CloseDriver()
+	if len(flags.ScreenshotPath) > 0 {
+		if err := RunScreenshot(flags); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Saved %s\n", flags.ScreenshotPath)
+		return
+	}
 
-	cfg, err := LoadConfig("config.json")
-	if os.IsNotExist(err) || len(cfg.MidiDevice) == 0 {
-		cfg, err = CreateConfig()
+	cfg, err := LoadConfig(flags.ConfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		// fail rather than run the setup wizard, which would overwrite a file that may have been edited by hand
+		log.Fatalf("LoadConfig(%s): %v", flags.ConfigPath, err)
+	}
+	if flags.Reconfigure || os.IsNotExist(err) || len(cfg.Profiles) == 0 {
+		p, err := CreateProfile(prompt.New(os.Stdin, os.Stdout), os.Stdout)
 		if err != nil {
 			log.Fatal(err)
 		}
-		err = SaveConfig("config.json", cfg)
+		cfg.SetProfile(p)
+		err = SaveConfig(flags.ConfigPath, cfg)
 		if err != nil {
 			log.Fatal(err)
 		}
+		fmt.Printf("Saved %s\n", flags.ConfigPath)
 	} else {
-		fmt.Println("Loaded config.json")
+		fmt.Printf("Loaded %s\n", flags.ConfigPath)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("%s: %v", flags.ConfigPath, err)
+	}
+
+	profile, err := selectProfile(cfg, flags)
+	if err != nil {
+		log.Fatalf("%s: %v (use -reconfigure to add a profile)", flags.ConfigPath, err)
+	}
+	fmt.Printf("Using profile \"%s\"\n", profile.Name)
+
+	dialSVG, err := loadDial(flags.DialPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fnt, err := loadFont(flags.FontPath)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	mgr := NewSceneMgr()
-	midiMgr, err := NewMidiMgr(cfg)
+	midiMgr, err := NewMidiMgr(profile)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer midiMgr.Close()
-	// mgr.AddScene(SceneSplash, NewSplashScene())
+
+	theme, err := cfg.SelectTheme(flags.Theme)
+	if err != nil {
+		log.Fatalf("%s: %v", flags.ConfigPath, err)
+	}
+
+	midiOut := NewMidiOut()
+	if err := midiOut.Apply(cfg.MidiOut); err != nil {
+		log.Fatalf("midi_out: %v", err)
+	}
+	defer midiOut.Close()
+
+	sounds := MultiSounds{midiOut}
+	var audio *AudioSounds
+	if !flags.NoAudio {
+		audio = NewAudioSounds(cfg.SoundVolume())
+		sounds = append(sounds, audio)
+	}
+
+	var scene *GameScene
+	cfgWatcher := NewConfigWatcher(flags.ConfigPath, func(path string) (Config, error) {
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			return Config{}, err
+		}
+		return cfg, cfg.Validate()
+	}, func(cfg Config) error {
+		profile, err := selectProfile(cfg, flags)
+		if err != nil {
+			return err
+		}
+		theme, err := cfg.SelectTheme(flags.Theme)
+		if err != nil {
+			return err
+		}
+		scene.ReloadThemes(cfg.AllThemes(), theme.Name)
+		if audio != nil {
+			audio.SetVolume(cfg.SoundVolume())
+		}
+		if err := midiOut.Apply(cfg.MidiOut); err != nil {
+			return fmt.Errorf("midi_out: %w", err)
+		}
+		return midiMgr.ApplyProfile(profile)
+	})
+	defer cfgWatcher.Close()
+
+	scene = NewGameScene(midiMgr, cfgWatcher, dialSVG, fnt)
+	scene.SetThemes(cfg.AllThemes(), theme.Name)
+	scene.SetSounds(sounds)
+	// mgr.AddScene(SceneSplash, NewSplashScene(scene)) // adds the game scene once it's done
 	// mgr.SwitchScene(SceneSplash)
-	mgr.AddScene(SceneGame, NewGameScene(midiMgr))
+	mgr.AddScene(SceneGame, scene)
 	mgr.SwitchScene(SceneGame)
 
-	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowSize(flags.WindowWidth, flags.WindowHeight)
+	ebiten.SetFullscreen(flags.Fullscreen)
 	ebiten.SetWindowTitle("spin click (friday 02)")
 	if err := ebiten.RunGame(mgr); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func CreateConfig() (Config, error) {
-	fmt.Println("MIDI device not configured.")
+// loadDial loads the SVG to draw the dial from, or returns nil if path is empty.
+func loadDial(path string) (*SVGImage, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	img, err := LoadSVG(path)
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+// loadFont loads the font to draw text with, or returns nil (for the default font) if path is empty.
+func loadFont(path string) (*Font, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	return LoadFont(path)
+}
+
+// selectProfile returns the profile chosen on the command line, or else the first
+// profile that matches an available MIDI device, with any device override applied.
+func selectProfile(cfg Config, flags Flags) (Profile, error) {
+	p, err := cfg.SelectProfile(flags.Profile, inPortNames())
+	if err != nil {
+		return Profile{}, err
+	}
+	if len(flags.Device) > 0 {
+		p.MidiDevice = flags.Device
+	}
+	return p, nil
+}
 
-	fmt.Printf("\nListing MIDI devices...\n")
+func CreateProfile(pr *prompt.Prompter, w io.Writer) (Profile, error) {
+	fmt.Fprintln(w, "MIDI device not configured.")
+
+	fmt.Fprintf(w, "\nListing MIDI devices...\n")
 	inPorts := midi.GetInPorts()
+	if len(inPorts) == 0 {
+		return Profile{}, fmt.Errorf("no MIDI input ports found")
+	}
 
-	for i, port := range inPorts {
-		fmt.Printf("%2d: %s (#%d)\n", i, port.String(), port.Number())
+	port, err := ChooseInPort(pr, inPorts)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	knobs, err := ReadKnobs(pr, w, port)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	p := Profile{
+		Name:       port.String(),
+		MidiDevice: port.String(),
+		Knobs:      knobs,
+	}
+
+	b, err := json.MarshalIndent(p, "  ", "  ")
+	if err != nil {
+		return Profile{}, err
 	}
+	fmt.Fprintf(w, "\nProfile created: \n%s\n", string(b))
 
-	n := MustReadNumber(0, len(inPorts)-1, "\nChoose your MIDI device")
-	port := inPorts[n]
+	return p, nil
+}
 
+// ReadKnobs listens to the given port (printing any control change messages it
+// sees, to help the user find the right values), while asking the user for the
+// channel and controller of each knob.
+func ReadKnobs(pr *prompt.Prompter, w io.Writer, port drivers.In) ([]KnobConfig, error) {
 	stop, err := midi.ListenTo(port, func(msg midi.Message, timestampms int32) {
 		var ch, controller, value uint8
 		switch {
 		case msg.GetControlChange(&ch, &controller, &value):
-			fmt.Printf("control change: channel=%v, controller=%v, value=%v\n", ch, controller, value)
+			fmt.Fprintf(w, "control change: channel=%v, controller=%v, value=%v\n", ch, controller, value)
 		default:
 			// ignore
 		}
 	})
 	if err != nil {
-		return Config{}, err
-	}
-
-	cfg := Config{
-		MidiDevice: port.String(),
-		Knobs:      make([]KnobConfig, KNOB_COUNT),
+		return nil, err
 	}
+	defer stop()
 
-	fmt.Printf("\nMIDI device %s active. Turn knobs to print control change messages.\n", port.String())
+	fmt.Fprintf(w, "\nMIDI device %s active. Turn knobs to print control change messages.\n", port.String())
 
-	for i := 0; i < KNOB_COUNT; i++ {
-		cfg.Knobs[i].Channel = MustReadNumber(0, 15, fmt.Sprintf("\nChoose the Channel for Knob %d", i))
-		cfg.Knobs[i].Controller = MustReadNumber(0, 127, fmt.Sprintf("\nChoose the Controller for Knob %d", i))
+	knobs := make([]KnobConfig, KNOB_COUNT)
+	for i := range knobs {
+		knobs[i].Channel, err = pr.IntRange(fmt.Sprintf("\nChoose the Channel for Knob %d", i), 0, 15)
+		if err != nil {
+			return nil, err
+		}
+		knobs[i].Controller, err = pr.IntRange(fmt.Sprintf("\nChoose the Controller for Knob %d", i), 0, 127)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	stop()
+	return knobs, nil
+}
 
-	b, err := json.MarshalIndent(cfg, "  ", "  ")
-	if err != nil {
-		return Config{}, err
+func printInPorts(w io.Writer, inPorts midi.InPorts) {
+	for i, port := range inPorts {
+		fmt.Fprintf(w, "%2d: %s (#%d)\n", i, port.String(), port.Number())
 	}
-	fmt.Printf("\nConfig created: \n%s\n", string(b))
-
-	return cfg, nil
 }
 
-func MustReadNumber(min, max int, msg string) int {
-	punctuation := ":"
-	if strings.HasSuffix(msg, "?") {
-		punctuation = "?"
-		msg = msg[:len(msg)-1]
+func printOutPorts(w io.Writer, outPorts midi.OutPorts) {
+	for i, port := range outPorts {
+		fmt.Fprintf(w, "%2d: %s (#%d)\n", i, port.String(), port.Number())
 	}
+}
 
-	fmt.Printf("%s [%d-%d]%s ", msg, min, max, punctuation)
-
-try_again:
-	reader := bufio.NewReader(os.Stdin)
-	line, err := reader.ReadString('\n')
-	if err != nil {
-		panic(err)
+// ChooseInPort lists the given ports, and asks the user to pick one.
+func ChooseInPort(pr *prompt.Prompter, inPorts midi.InPorts) (drivers.In, error) {
+	choices := make([]string, len(inPorts))
+	for i, port := range inPorts {
+		choices[i] = fmt.Sprintf("%s (#%d)", port.String(), port.Number())
 	}
-
-	// remove the delimeter from the string
-	line = strings.TrimSpace(line)
-
-	n, err := strconv.Atoi(line)
+	n, err := pr.Choose("\nChoose your MIDI device", choices)
 	if err != nil {
-		fmt.Printf("Invalid input: %s\nPlease enter a number in the range [%d-%d]: ", err.Error(), min, max)
-		goto try_again
+		return nil, err
 	}
-
-	if n < min || n > max {
-		fmt.Printf("Invalid input: %d\nPlease enter a number in the range [%d-%d]: ", n, min, max)
-		goto try_again
-	}
-
-	return n
+	return inPorts[n], nil
 }
@@ -1,5 +1,10 @@
 package main
 
+import (
+	"errors"
+	"math"
+)
+
 // Matrix3x3 is a 3x3 matrix stored in row-major order.
 // [0 1 2]
 // [3 4 5]
@@ -36,3 +41,150 @@ func (m Matrix3x3) MultiplyVec2D(v Vec2D) Vec2D {
 		m[3]*v.X + m[4]*v.Y + m[5],
 	}
 }
+
+// MaxScale returns how much m stretches the longer of the x and y axes. It's exact
+// unless m includes shear, in which case it underestimates slightly.
+func (m Matrix3x3) MaxScale() float32 {
+	sx := math.Hypot(float64(m[0]), float64(m[3]))
+	sy := math.Hypot(float64(m[1]), float64(m[4]))
+	return float32(math.Max(sx, sy))
+}
+
+// UniformScale returns how much m scales everything by, if it scales every
+// direction by the same amount (i.e. it may translate, rotate and reflect,
+// but doesn't stretch or shear). Otherwise it returns false.
+func (m Matrix3x3) UniformScale() (float32, bool) {
+	sx := math.Hypot(float64(m[0]), float64(m[3]))
+	sy := math.Hypot(float64(m[1]), float64(m[4]))
+	dot := float64(m[0])*float64(m[1]) + float64(m[3])*float64(m[4])
+	const epsilon = 1e-4
+	if math.Abs(sx-sy) > epsilon*sx || math.Abs(dot) > epsilon*sx*sy {
+		return 0, false
+	}
+	return float32(sx), true
+}
+
+// ErrSingularMatrix is returned when inverting a matrix that has no inverse.
+var ErrSingularMatrix = errors.New("matrix is singular")
+
+// Translation3x3 returns a matrix that moves points by (x,y).
+func Translation3x3(x, y float32) Matrix3x3 {
+	return Matrix3x3{
+		1, 0, x,
+		0, 1, y,
+		0, 0, 1,
+	}
+}
+
+// Rotation3x3 returns a matrix that rotates points around the origin by rad radians.
+// With y pointing down (as it does on screen), positive angles rotate clockwise.
+func Rotation3x3(rad float32) Matrix3x3 {
+	s, c := math.Sincos(float64(rad))
+	return Matrix3x3{
+		float32(c), float32(-s), 0,
+		float32(s), float32(c), 0,
+		0, 0, 1,
+	}
+}
+
+// Scale3x3 returns a matrix that scales points away from the origin.
+func Scale3x3(x, y float32) Matrix3x3 {
+	return Matrix3x3{
+		x, 0, 0,
+		0, y, 0,
+		0, 0, 1,
+	}
+}
+
+// Shear3x3 returns a matrix that shears points, adding x times each point's y
+// to its x, and y times each point's x to its y.
+func Shear3x3(x, y float32) Matrix3x3 {
+	return Matrix3x3{
+		1, x, 0,
+		y, 1, 0,
+		0, 0, 1,
+	}
+}
+
+// Reflection3x3 returns a matrix that mirrors points across the line that
+// passes through the origin in the direction (x,y).
+func Reflection3x3(x, y float32) Matrix3x3 {
+	lenSq := x*x + y*y
+	if lenSq == 0 {
+		return Identity3x3()
+	}
+	// Householder reflection across the line: 2∙d∙dᵀ/|d|² - I
+	xx := 2 * x * x / lenSq
+	xy := 2 * x * y / lenSq
+	yy := 2 * y * y / lenSq
+	return Matrix3x3{
+		xx - 1, xy, 0,
+		xy, yy - 1, 0,
+		0, 0, 1,
+	}
+}
+
+func (m Matrix3x3) Determinant() float32 {
+	return m[0]*(m[4]*m[8]-m[5]*m[7]) -
+		m[1]*(m[3]*m[8]-m[5]*m[6]) +
+		m[2]*(m[3]*m[7]-m[4]*m[6])
+}
+
+func (m Matrix3x3) Transpose() Matrix3x3 {
+	return Matrix3x3{
+		m[0], m[3], m[6],
+		m[1], m[4], m[7],
+		m[2], m[5], m[8],
+	}
+}
+
+// Inverse returns the matrix that undoes m, such that m∙Inverse(m) is the identity.
+func (m Matrix3x3) Inverse() (Matrix3x3, error) {
+	det := m.Determinant()
+	if det == 0 {
+		return Matrix3x3{}, ErrSingularMatrix
+	}
+
+	// adjugate (transpose of the cofactor matrix), divided by the determinant
+	inv := 1 / det
+	return Matrix3x3{
+		(m[4]*m[8] - m[5]*m[7]) * inv,
+		(m[2]*m[7] - m[1]*m[8]) * inv,
+		(m[1]*m[5] - m[2]*m[4]) * inv,
+		(m[5]*m[6] - m[3]*m[8]) * inv,
+		(m[0]*m[8] - m[2]*m[6]) * inv,
+		(m[2]*m[3] - m[0]*m[5]) * inv,
+		(m[3]*m[7] - m[4]*m[6]) * inv,
+		(m[1]*m[6] - m[0]*m[7]) * inv,
+		(m[0]*m[4] - m[1]*m[3]) * inv,
+	}, nil
+}
+
+// Decompose splits an affine matrix into the values that rebuild it via
+// Translation3x3(pos)∙Rotation3x3(rot)∙Shear3x3(shear, 0)∙Scale3x3(scale).
+// Reflections come back as a negative scale.Y. If the matrix collapses the
+// x axis to a point, only the translation is meaningful.
+func (m Matrix3x3) Decompose() (pos Vec2D, rot float32, scale Vec2D, shear float32) {
+	pos = Vec2D{m[2], m[5]}
+
+	// the first column is the x axis, which only rotation and scale.X affect
+	a, c := float64(m[0]), float64(m[3])
+	sx := math.Hypot(a, c)
+	if sx == 0 {
+		return pos, 0, Vec2D{}, 0
+	}
+	r := math.Atan2(c, a)
+
+	// un-rotate the second column (the y axis) to get [shear*scale.Y, scale.Y]
+	sin, cos := math.Sincos(r)
+	b, d := float64(m[1]), float64(m[4])
+	shy := cos*b + sin*d
+	sy := cos*d - sin*b
+
+	var sh float64
+	if sy != 0 {
+		sh = shy / sy
+	}
+
+	return pos, float32(r), Vec2D{float32(sx), float32(sy)}, float32(sh)
+}
@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func assertMatrix(t *testing.T, m, n Matrix3x3) {
 	t.Helper()
@@ -38,3 +41,178 @@ func TestMatrix3x3_Multiply(t *testing.T) {
 		18, 24, 30,
 	})
 }
+
+func assertMatrixNear(t *testing.T, m, n Matrix3x3) {
+	t.Helper()
+	for i := 0; i < 9; i++ {
+		if math.Abs(float64(m[i]-n[i])) > 1e-5 {
+			t.Errorf("matrix mismatch: %v != %v", m, n)
+			return
+		}
+	}
+}
+
+func assertNear(t *testing.T, name string, a, b float32) {
+	t.Helper()
+	if math.Abs(float64(a-b)) > 1e-5 {
+		t.Errorf("%s mismatch: %v != %v", name, a, b)
+	}
+}
+
+func TestMatrix3x3_Constructors(t *testing.T) {
+	cases := []struct {
+		Name   string
+		M      Matrix3x3
+		In     Vec2D
+		Expect Vec2D
+	}{
+		{"translate", Translation3x3(3, -4), Vec2D{1, 2}, Vec2D{4, -2}},
+		{"rotate 90", Rotation3x3(math.Pi / 2), Vec2D{1, 0}, Vec2D{0, 1}},
+		{"rotate 180", Rotation3x3(math.Pi), Vec2D{1, 2}, Vec2D{-1, -2}},
+		{"scale", Scale3x3(2, 3), Vec2D{1, 2}, Vec2D{2, 6}},
+		{"shear x", Shear3x3(2, 0), Vec2D{1, 3}, Vec2D{7, 3}},
+		{"shear y", Shear3x3(0, 2), Vec2D{3, 1}, Vec2D{3, 7}},
+		{"reflect across x axis", Reflection3x3(1, 0), Vec2D{1, 2}, Vec2D{1, -2}},
+		{"reflect across y axis", Reflection3x3(0, 5), Vec2D{1, 2}, Vec2D{-1, 2}},
+		{"reflect across diagonal", Reflection3x3(1, 1), Vec2D{1, 2}, Vec2D{2, 1}},
+		{"reflect zero axis", Reflection3x3(0, 0), Vec2D{1, 2}, Vec2D{1, 2}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			v := tc.M.MultiplyVec2D(tc.In)
+			assertNear(t, "x", v.X, tc.Expect.X)
+			assertNear(t, "y", v.Y, tc.Expect.Y)
+		})
+	}
+}
+
+func TestMatrix3x3_Determinant(t *testing.T) {
+	cases := []struct {
+		Name   string
+		M      Matrix3x3
+		Expect float32
+	}{
+		{"identity", Identity3x3(), 1},
+		{"translate", Translation3x3(10, 20), 1},
+		{"rotate", Rotation3x3(1.2), 1},
+		{"scale", Scale3x3(2, 3), 6},
+		{"shear", Shear3x3(5, 0), 1},
+		{"reflect", Reflection3x3(1, 2), -1},
+		{"singular", Matrix3x3{1, 2, 3, 4, 5, 6, 7, 8, 9}, 0},
+		{"general", Matrix3x3{2, 0, 1, 1, 3, 2, 1, 1, 2}, 6},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assertNear(t, "determinant", tc.M.Determinant(), tc.Expect)
+		})
+	}
+}
+
+func TestMatrix3x3_Transpose(t *testing.T) {
+	a := Matrix3x3{
+		1, 2, 3,
+		4, 5, 6,
+		7, 8, 9,
+	}
+	assertMatrix(t, a.Transpose(), Matrix3x3{
+		1, 4, 7,
+		2, 5, 8,
+		3, 6, 9,
+	})
+	assertMatrix(t, a.Transpose().Transpose(), a)
+}
+
+func TestMatrix3x3_Inverse(t *testing.T) {
+	cases := []struct {
+		Name string
+		M    Matrix3x3
+		Err  error
+	}{
+		{"identity", Identity3x3(), nil},
+		{"translate", Translation3x3(10, -20), nil},
+		{"rotate", Rotation3x3(0.7), nil},
+		{"scale", Scale3x3(2, 0.5), nil},
+		{"shear", Shear3x3(0.3, -0.2), nil},
+		{"reflect", Reflection3x3(3, 1), nil},
+		{"combined", Translation3x3(5, 6).Multiply(Rotation3x3(2)).Multiply(Scale3x3(3, -2)), nil},
+		{"zero scale", Scale3x3(0, 1), ErrSingularMatrix},
+		{"singular", Matrix3x3{1, 2, 3, 4, 5, 6, 7, 8, 9}, ErrSingularMatrix},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			inv, err := tc.M.Inverse()
+			if err != tc.Err {
+				t.Fatalf("expected error %v, got %v", tc.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			assertMatrixNear(t, tc.M.Multiply(inv), Identity3x3())
+			assertMatrixNear(t, inv.Multiply(tc.M), Identity3x3())
+		})
+	}
+}
+
+func TestMatrix3x3_Decompose(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Pos   Vec2D
+		Rot   float32
+		Scale Vec2D
+		Shear float32
+	}{
+		{"identity", Vec2D{0, 0}, 0, Vec2D{1, 1}, 0},
+		{"translate", Vec2D{10, -20}, 0, Vec2D{1, 1}, 0},
+		{"rotate", Vec2D{0, 0}, 1.1, Vec2D{1, 1}, 0},
+		{"rotate negative", Vec2D{0, 0}, -2.5, Vec2D{1, 1}, 0},
+		{"scale", Vec2D{0, 0}, 0, Vec2D{2, 3}, 0},
+		{"shear", Vec2D{0, 0}, 0, Vec2D{1, 1}, 0.5},
+		{"reflect", Vec2D{0, 0}, 0, Vec2D{1, -1}, 0},
+		{"everything", Vec2D{3, 4}, 0.6, Vec2D{2, 0.5}, -0.25},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			m := Translation3x3(tc.Pos.X, tc.Pos.Y).
+				Multiply(Rotation3x3(tc.Rot)).
+				Multiply(Shear3x3(tc.Shear, 0)).
+				Multiply(Scale3x3(tc.Scale.X, tc.Scale.Y))
+
+			pos, rot, scale, shear := m.Decompose()
+			assertNear(t, "pos.X", pos.X, tc.Pos.X)
+			assertNear(t, "pos.Y", pos.Y, tc.Pos.Y)
+			assertNear(t, "rot", rot, tc.Rot)
+			assertNear(t, "scale.X", scale.X, tc.Scale.X)
+			assertNear(t, "scale.Y", scale.Y, tc.Scale.Y)
+			assertNear(t, "shear", shear, tc.Shear)
+		})
+	}
+}
+
+func TestMatrix3x3_UniformScale(t *testing.T) {
+	cases := []struct {
+		Name     string
+		M        Matrix3x3
+		Scale    float32
+		Expected bool
+	}{
+		{"identity", Identity3x3(), 1, true},
+		{"rotate and scale", Translation3x3(5, 6).Multiply(Rotation3x3(0.7)).Multiply(Scale3x3(3, 3)), 3, true},
+		{"reflect", Rotation3x3(0.3).Multiply(Scale3x3(-2, 2)), 2, true},
+		{"stretch", Rotation3x3(0.3).Multiply(Scale3x3(2, 3)), 0, false},
+		{"shear", Shear3x3(0.5, 0), 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			scale, ok := tc.M.UniformScale()
+			if ok != tc.Expected {
+				t.Fatalf("expected %v, got %v", tc.Expected, ok)
+			}
+			assertNear(t, "scale", scale, tc.Scale)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 const KNOB_COUNT = 4
 
 type MidiMgr struct {
+	device  string
 	in      drivers.In
 	stop    func()
 	knobVal [KNOB_COUNT]int
@@ -18,28 +19,65 @@ type MidiMgr struct {
 }
 
 type midiMgrLockState struct {
-	knob [KNOB_COUNT]atomic.Int32
+	knob  [KNOB_COUNT]atomic.Int32
+	knobs atomic.Pointer[[]KnobConfig]
 }
 
-func NewMidiMgr(cfg Config) (*MidiMgr, error) {
-	in, err := midi.FindInPort(cfg.MidiDevice)
+func (s *midiMgrLockState) setKnobs(knobs []KnobConfig) {
+	// TODO: once Go 1.21 comes out: `knobs = slices.Clone(knobs[:min(len(knobs), KNOB_COUNT)])`
+	n := len(knobs)
+	if n > KNOB_COUNT {
+		n = KNOB_COUNT
+	}
+	cp := make([]KnobConfig, n)
+	copy(cp, knobs)
+	s.knobs.Store(&cp)
+}
+
+func NewMidiMgr(p Profile) (*MidiMgr, error) {
+	m := &MidiMgr{
+		shared: &midiMgrLockState{},
+	}
+	m.shared.setKnobs(p.Knobs)
+
+	in, stop, err := m.listen(p.MidiDevice)
 	if err != nil {
-		return nil, fmt.Errorf("FindInPort(%s): %w", cfg.MidiDevice, err)
+		return nil, err
 	}
+	m.device = p.MidiDevice
+	m.in = in
+	m.stop = stop
+
+	return m, nil
+}
 
-	shared := &midiMgrLockState{}
+// NewFixedMidiMgr returns a MidiMgr that isn't connected to a device, and
+// whose knobs stay at the given values (e.g. to render a known state).
+func NewFixedMidiMgr(knobs [KNOB_COUNT]int) *MidiMgr {
+	m := &MidiMgr{
+		shared: &midiMgrLockState{},
+		stop:   func() {},
+	}
+	m.shared.setKnobs(nil)
+	for i, v := range knobs {
+		m.shared.knob[i].Store(int32(v))
+	}
+	return m
+}
+
+func (m *MidiMgr) listen(device string) (drivers.In, func(), error) {
+	in, err := midi.FindInPort(device)
+	if err != nil {
+		return nil, nil, fmt.Errorf("FindInPort(%s): %w", device, err)
+	}
+
+	shared := m.shared
 
 	stop, err := midi.ListenTo(in, func(msg midi.Message, timestampms int32) {
 		var ch, controller, value uint8
 		switch {
 		case msg.GetControlChange(&ch, &controller, &value):
-			// TODO: once Go 1.21 comes out: `max := min(len(cfg.Knobs), KNOB_COUNT)`
-			max := len(cfg.Knobs)
-			if max > KNOB_COUNT {
-				max = KNOB_COUNT
-			}
-			for i := 0; i < max; i++ {
-				knob := cfg.Knobs[i]
+			for i, knob := range *shared.knobs.Load() {
 				if ch == uint8(knob.Channel) && controller == uint8(knob.Controller) {
 					shared.knob[i].Store(int32(value))
 				}
@@ -49,14 +87,29 @@ func NewMidiMgr(cfg Config) (*MidiMgr, error) {
 		}
 	})
 	if err != nil {
-		return nil, fmt.Errorf("ListenTo(%s): %w", cfg.MidiDevice, err)
+		return nil, nil, fmt.Errorf("ListenTo(%s): %w", device, err)
 	}
 
-	return &MidiMgr{
-		in:     in,
-		stop:   stop,
-		shared: shared,
-	}, nil
+	return in, stop, nil
+}
+
+// ApplyProfile swaps in new knob bindings, and switches MIDI devices if the
+// device has changed. Current knob values are kept.
+func (m *MidiMgr) ApplyProfile(p Profile) error {
+	if p.MidiDevice != m.device {
+		// open the new device before closing the old, so a bad device name leaves the old one working
+		in, stop, err := m.listen(p.MidiDevice)
+		if err != nil {
+			return err
+		}
+		m.stop()
+		m.device = p.MidiDevice
+		m.in = in
+		m.stop = stop
+	}
+
+	m.shared.setKnobs(p.Knobs)
+	return nil
 }
 
 func (m *MidiMgr) Close() {
@@ -72,3 +125,13 @@ func (m *MidiMgr) Update() {
 func (m *MidiMgr) Knob(n int) int {
 	return m.knobVal[n]
 }
+
+// inPortNames returns the names of all available MIDI input ports.
+func inPortNames() []string {
+	inPorts := midi.GetInPorts()
+	names := make([]string, len(inPorts))
+	for i, port := range inPorts {
+		names[i] = port.String()
+	}
+	return names
+}
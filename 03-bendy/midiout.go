@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+const (
+	defaultVelocity     = 100
+	defaultNoteDuration = 100 * time.Millisecond
+)
+
+// MidiOut plays the game's sounds by sending MIDI messages to an output
+// port, as mapped by a MidiOutConfig. Notes are stopped by timers, so they
+// end on time however often Play is called.
+type MidiOut struct {
+	mu      sync.Mutex // guards everything below, as timers send from other Go routines
+	device  string
+	out     drivers.Out
+	send    func(msg midi.Message) error // nil when not connected
+	events  [soundCount][]MidiEventConfig
+	playing map[midiNote]uint64 // notes that are on, and which Play started them
+	plays   uint64
+	failed  bool // whether the last send failed, so each run of failures is only logged once
+
+	// afterFunc calls f after d; replaced in tests
+	afterFunc func(d time.Duration, f func())
+}
+
+type midiNote struct {
+	channel, key uint8
+}
+
+// NewMidiOut returns a MidiOut that isn't connected to a port, so it plays
+// nothing until Apply is given a config.
+func NewMidiOut() *MidiOut {
+	return &MidiOut{
+		playing: make(map[midiNote]uint64),
+		afterFunc: func(d time.Duration, f func()) {
+			time.AfterFunc(d, f)
+		},
+	}
+}
+
+// Apply swaps in the events from cfg, and switches ports if the device has
+// changed. A nil cfg disconnects from the port.
+func (m *MidiOut) Apply(cfg *MidiOutConfig) error {
+	var events [soundCount][]MidiEventConfig
+	if cfg != nil {
+		for _, e := range cfg.Events {
+			s, err := parseSound(e.Sound)
+			if err != nil {
+				return err
+			}
+			events[s] = append(events[s], e)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cfg == nil || cfg.Device != m.device {
+		var out drivers.Out
+		var send func(msg midi.Message) error
+		if cfg != nil {
+			// open the new port before closing the old, so a bad device name leaves the old one working
+			var err error
+			out, err = midi.FindOutPort(cfg.Device)
+			if err != nil {
+				return fmt.Errorf("FindOutPort(%s): %w", cfg.Device, err)
+			}
+			send, err = midi.SendTo(out)
+			if err != nil {
+				return fmt.Errorf("SendTo(%s): %w", cfg.Device, err)
+			}
+		}
+		m.disconnect()
+		m.out, m.send = out, send
+		if cfg != nil {
+			m.device = cfg.Device
+		}
+	}
+
+	m.events = events
+	return nil
+}
+
+// Play sends the messages mapped to s.
+func (m *MidiOut) Play(s Sound) {
+	if s < 0 || s >= soundCount {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.send == nil {
+		return
+	}
+
+	m.plays++
+	play := m.plays
+	for _, e := range m.events[s] {
+		ch := uint8(e.Channel)
+		if e.Controller != nil {
+			m.sendLocked(midi.ControlChange(ch, uint8(*e.Controller), uint8(e.Value)))
+			continue
+		}
+
+		note := midiNote{ch, uint8(*e.Note)}
+		if _, ok := m.playing[note]; ok {
+			// end the note first, so the synth starts it again
+			m.sendLocked(midi.NoteOff(note.channel, note.key))
+		}
+		velocity := uint8(e.Velocity)
+		if velocity == 0 {
+			velocity = defaultVelocity
+		}
+		m.sendLocked(midi.NoteOn(note.channel, note.key, velocity))
+		m.playing[note] = play
+
+		duration := time.Duration(e.DurationMs) * time.Millisecond
+		if duration <= 0 {
+			duration = defaultNoteDuration
+		}
+		m.afterFunc(duration, func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			// a later Play may have restarted the note, in which case it's up to that one to end it
+			if m.playing[note] == play {
+				m.sendLocked(midi.NoteOff(note.channel, note.key))
+				delete(m.playing, note)
+			}
+		})
+	}
+}
+
+// Close stops any notes that are playing, and disconnects from the port.
+func (m *MidiOut) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.disconnect()
+}
+
+// disconnect stops any notes that are playing, so none are left hanging, and
+// closes the port. m.mu must be held.
+func (m *MidiOut) disconnect() {
+	for note := range m.playing {
+		m.sendLocked(midi.NoteOff(note.channel, note.key))
+		delete(m.playing, note)
+	}
+	if m.out != nil {
+		m.out.Close()
+	}
+	m.device, m.out, m.send = "", nil, nil
+}
+
+// sendLocked sends msg, logging the first of any run of failures (rather
+// than one per message, which could be many per second). m.mu must be held.
+func (m *MidiOut) sendLocked(msg midi.Message) {
+	if m.send == nil {
+		return
+	}
+	err := m.send(msg)
+	if err != nil && !m.failed {
+		log.Printf("MIDI out (%s): %v", m.device, err)
+	}
+	m.failed = err != nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// newTestMidiOut returns a MidiOut connected to nothing but a list of the
+// messages it sends, whose timers only fire when the test calls them.
+func newTestMidiOut(events []MidiEventConfig) (m *MidiOut, sent *[]string, timers *[]func()) {
+	m = NewMidiOut()
+	sent, timers = &[]string{}, &[]func(){}
+	m.send = func(msg midi.Message) error {
+		*sent = append(*sent, msg.String())
+		return nil
+	}
+	m.afterFunc = func(d time.Duration, f func()) {
+		*timers = append(*timers, f)
+	}
+	for _, e := range events {
+		s, err := parseSound(e.Sound)
+		if err != nil {
+			panic(err)
+		}
+		m.events[s] = append(m.events[s], e)
+	}
+	return m, sent, timers
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func assertSent(t *testing.T, sent []string, expected ...midi.Message) {
+	t.Helper()
+	if len(sent) != len(expected) {
+		t.Fatalf("expected %d messages, got %d: %v", len(expected), len(sent), sent)
+	}
+	for i, msg := range expected {
+		if sent[i] != msg.String() {
+			t.Errorf("message %d: expected %s, got %s", i, msg, sent[i])
+		}
+	}
+}
+
+func TestMidiOut_Play(t *testing.T) {
+	m, sent, timers := newTestMidiOut([]MidiEventConfig{
+		{Sound: "tick", Channel: 9, Controller: intPtr(20), Value: 64},
+		{Sound: "click", Channel: 1, Note: intPtr(60), Velocity: 90},
+		{Sound: "click", Channel: 1, Note: intPtr(67)},
+	})
+
+	m.Play(SoundTick)
+	assertSent(t, *sent, midi.ControlChange(9, 20, 64))
+	if len(*timers) != 0 {
+		t.Errorf("expected control changes not to need ending, got %d timers", len(*timers))
+	}
+
+	*sent = nil
+	m.Play(SoundClick)
+	assertSent(t, *sent, midi.NoteOn(1, 60, 90), midi.NoteOn(1, 67, defaultVelocity))
+
+	*sent = nil
+	for _, f := range *timers {
+		f()
+	}
+	assertSent(t, *sent, midi.NoteOff(1, 60), midi.NoteOff(1, 67))
+}
+
+func TestMidiOut_RestartsPlayingNotes(t *testing.T) {
+	m, sent, timers := newTestMidiOut([]MidiEventConfig{
+		{Sound: "tick", Channel: 0, Note: intPtr(72)},
+	})
+
+	m.Play(SoundTick)
+	m.Play(SoundTick)
+	assertSent(t, *sent, midi.NoteOn(0, 72, defaultVelocity), midi.NoteOff(0, 72), midi.NoteOn(0, 72, defaultVelocity))
+
+	// the first note's timer doesn't cut the second note short
+	*sent = nil
+	(*timers)[0]()
+	assertSent(t, *sent)
+	(*timers)[1]()
+	assertSent(t, *sent, midi.NoteOff(0, 72))
+}
+
+func TestMidiOut_CloseEndsNotes(t *testing.T) {
+	m, sent, timers := newTestMidiOut([]MidiEventConfig{
+		{Sound: "click", Channel: 2, Note: intPtr(48)},
+	})
+
+	m.Play(SoundClick)
+	*sent = nil
+	m.Close()
+	assertSent(t, *sent, midi.NoteOff(2, 48))
+
+	// once closed, nothing more is sent, even by timers that were waiting
+	*sent = nil
+	(*timers)[0]()
+	m.Play(SoundClick)
+	assertSent(t, *sent)
+}
+
+func TestMidiEventConfig_Validate(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Event MidiEventConfig
+		Valid bool
+	}{
+		{"note", MidiEventConfig{Sound: "tick", Channel: 15, Note: intPtr(127), Velocity: 127, DurationMs: 50}, true},
+		{"controller", MidiEventConfig{Sound: "click", Controller: intPtr(0), Value: 127}, true},
+		{"unknown sound", MidiEventConfig{Sound: "boom", Note: intPtr(60)}, false},
+		{"neither", MidiEventConfig{Sound: "tick"}, false},
+		{"both", MidiEventConfig{Sound: "tick", Note: intPtr(60), Controller: intPtr(1)}, false},
+		{"bad channel", MidiEventConfig{Sound: "tick", Channel: 16, Note: intPtr(60)}, false},
+		{"bad note", MidiEventConfig{Sound: "tick", Note: intPtr(128)}, false},
+		{"bad velocity", MidiEventConfig{Sound: "tick", Note: intPtr(60), Velocity: -1}, false},
+		{"bad duration", MidiEventConfig{Sound: "tick", Note: intPtr(60), DurationMs: -1}, false},
+		{"bad value", MidiEventConfig{Sound: "tick", Controller: intPtr(1), Value: 200}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.Event.Validate()
+			if tc.Valid && err != nil {
+				t.Errorf("expected valid, got %v", err)
+			}
+			if !tc.Valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
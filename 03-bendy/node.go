@@ -0,0 +1,102 @@
+package main
+
+// Node is an entry in a tree of transforms. Its world matrix is its parent's
+// world matrix times its own local transform (Xfm), so moving a node moves all
+// of its descendants with it.
+//
+// World matrices are cached, and are only recalculated when the node's Xfm or
+// any of its ancestors have changed. Since children point to their parent, a
+// Node must not be copied once it is in a tree.
+type Node struct {
+	Xfm Transform2D
+
+	parent   *Node
+	children []*Node
+
+	world         Matrix3x3
+	valid         bool   // false until world is first calculated, or after the parent changes
+	localVersion  uint64 // Xfm.version when world was last calculated
+	parentVersion uint64 // parent.worldVersion when world was last calculated
+	worldVersion  uint64 // incremented each time world is recalculated
+}
+
+func NewNode() *Node {
+	n := &Node{}
+	n.Init()
+	return n
+}
+
+// Init resets n to a detached node with an identity transform, for use when Node is embedded.
+func (n *Node) Init() {
+	*n = Node{Xfm: NewTransform2D()}
+}
+
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// AddChild attaches c to n, first detaching c from its current parent (if any).
+// It panics if c is n or one of n's ancestors, as that would make a cycle.
+func (n *Node) AddChild(c *Node) {
+	for a := n; a != nil; a = a.parent {
+		if a == c {
+			panic("Node.AddChild: can't add a node under itself or one of its descendants")
+		}
+	}
+	if c.parent != nil {
+		c.parent.RemoveChild(c)
+	}
+	c.parent = n
+	c.valid = false
+	n.children = append(n.children, c)
+}
+
+// RemoveChild detaches c from n, leaving c as the root of its own tree.
+func (n *Node) RemoveChild(c *Node) {
+	for i, child := range n.children {
+		if child == c {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			c.parent = nil
+			c.valid = false
+			return
+		}
+	}
+}
+
+// World returns the matrix that takes points from this node's local space to world space.
+func (n *Node) World() Matrix3x3 {
+	var parentWorld Matrix3x3
+	var parentVersion uint64
+	if n.parent != nil {
+		parentWorld = n.parent.World()
+		parentVersion = n.parent.worldVersion
+	}
+
+	if n.valid && n.localVersion == n.Xfm.version && n.parentVersion == parentVersion {
+		return n.world
+	}
+
+	if n.parent != nil {
+		n.world = parentWorld.Multiply(n.Xfm.Matrix())
+	} else {
+		n.world = n.Xfm.Matrix()
+	}
+	n.valid = true
+	n.localVersion = n.Xfm.version
+	n.parentVersion = parentVersion
+	n.worldVersion++
+
+	return n.world
+}
+
+// Walk calls fn for n and each of its descendants, parents before children.
+func (n *Node) Walk(fn func(*Node)) {
+	fn(n)
+	for _, c := range n.children {
+		c.Walk(fn)
+	}
+}
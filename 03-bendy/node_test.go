@@ -0,0 +1,163 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNode_World(t *testing.T) {
+	root := NewNode()
+	root.Xfm.SetPos(Vec2D{100, 50})
+	root.Xfm.SetRot(math.Pi / 2)
+
+	child := NewNode()
+	child.Xfm.SetPos(Vec2D{10, 0})
+	root.AddChild(child)
+
+	grandchild := NewNode()
+	grandchild.Xfm.SetUniformScale(2)
+	child.AddChild(grandchild)
+
+	// (1,0) -> scaled (2,0) -> moved (12,0) -> rotated (0,12) -> moved (100,62)
+	assertVec(t, grandchild.World().MultiplyVec2D(Vec2D{1, 0}), Vec2D{100, 62})
+
+	root.Xfm.SetRot(0)
+	assertVec(t, grandchild.World().MultiplyVec2D(Vec2D{1, 0}), Vec2D{112, 50})
+}
+
+func TestNode_DirtyPropagation(t *testing.T) {
+	root := NewNode()
+	a := NewNode()
+	b := NewNode()
+	a1 := NewNode()
+	root.AddChild(a)
+	root.AddChild(b)
+	a.AddChild(a1)
+
+	all := []*Node{root, a, b, a1}
+	for _, n := range all {
+		n.World()
+	}
+	counts := func() []uint64 {
+		var r []uint64
+		for _, n := range all {
+			r = append(r, n.worldVersion)
+		}
+		return r
+	}
+	assertCounts := func(expect []uint64) {
+		t.Helper()
+		got := counts()
+		for i := range expect {
+			if got[i] != expect[i] {
+				t.Errorf("recalculation counts: expected %v, got %v", expect, got)
+				return
+			}
+		}
+	}
+	assertCounts([]uint64{1, 1, 1, 1})
+
+	// nothing changed, so nothing is recalculated
+	for _, n := range all {
+		n.World()
+	}
+	assertCounts([]uint64{1, 1, 1, 1})
+
+	// changing a only affects a and its descendants
+	a.Xfm.SetPos(Vec2D{1, 2})
+	for _, n := range all {
+		n.World()
+	}
+	assertCounts([]uint64{1, 2, 1, 2})
+
+	// changing the root affects everything
+	root.Xfm.SetRot(1)
+	for _, n := range all {
+		n.World()
+	}
+	assertCounts([]uint64{2, 3, 2, 3})
+}
+
+func TestNode_Reparent(t *testing.T) {
+	p1 := NewNode()
+	p1.Xfm.SetPos(Vec2D{10, 0})
+	p2 := NewNode()
+	p2.Xfm.SetPos(Vec2D{0, 20})
+	c := NewNode()
+
+	p1.AddChild(c)
+	assertVec(t, c.World().MultiplyVec2D(Vec2D{}), Vec2D{10, 0})
+
+	p2.AddChild(c)
+	if len(p1.Children()) != 0 || c.Parent() != p2 {
+		t.Fatalf("child was not moved to the new parent")
+	}
+	assertVec(t, c.World().MultiplyVec2D(Vec2D{}), Vec2D{0, 20})
+
+	p2.RemoveChild(c)
+	assertVec(t, c.World().MultiplyVec2D(Vec2D{}), Vec2D{0, 0})
+}
+
+func TestNode_AddChildRefusesCycles(t *testing.T) {
+	root := NewNode()
+	child := NewNode()
+	grandchild := NewNode()
+	root.AddChild(child)
+	child.AddChild(grandchild)
+
+	cases := []struct {
+		Name   string
+		Parent *Node
+		Child  *Node
+	}{
+		{"itself", child, child},
+		{"parent under child", child, root},
+		{"grandparent under grandchild", grandchild, root},
+		{"parent under grandchild", grandchild, child},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic")
+				}
+				// and the tree is left as it was
+				if root.Parent() != nil || child.Parent() != root || grandchild.Parent() != child {
+					t.Errorf("expected the tree to be unchanged")
+				}
+				if len(root.Children()) != 1 || len(child.Children()) != 1 || len(grandchild.Children()) != 0 {
+					t.Errorf("expected the tree to be unchanged")
+				}
+			}()
+			tc.Parent.AddChild(tc.Child)
+		})
+	}
+
+	// moving a node under a sibling or cousin is fine
+	other := NewNode()
+	root.AddChild(other)
+	other.AddChild(grandchild)
+	if grandchild.Parent() != other {
+		t.Errorf("expected grandchild to move under other")
+	}
+}
+
+func TestNode_Walk(t *testing.T) {
+	root := NewNode()
+	a := NewNode()
+	b := NewNode()
+	a1 := NewNode()
+	root.AddChild(a)
+	root.AddChild(b)
+	a.AddChild(a1)
+
+	var order []*Node
+	root.Walk(func(n *Node) { order = append(order, n) })
+	expect := []*Node{root, a, a1, b}
+	for i := range expect {
+		if order[i] != expect[i] {
+			t.Fatalf("unexpected walk order at %d", i)
+		}
+	}
+}
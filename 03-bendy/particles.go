@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Emitter spawns particles, either steadily while Emitting, or all at once
+// with Burst, and moves them every Update until they die. Particles are small
+// squares, drawn through a Renderer along with everything else.
+type Emitter struct {
+	Pos      Vec2D   // in screen space
+	Radius   float32 // how far from Pos particles start, in the direction they head
+	Rate     float32 // particles spawned per tick while Emitting; fractions carry over
+	Emitting bool
+
+	Life        int     // ticks each particle lives
+	LifeSpread  int     // each particle's life is up to this many ticks longer or shorter
+	Angle       float32 // direction particles head, in radians
+	Spread      float32 // each particle heads up to this far either side of Angle; π for all around
+	Speed       float32 // pixels per tick
+	SpeedSpread float32 // each particle is up to this much faster or slower
+	Drag        float32 // fraction of its velocity a particle loses each tick
+	Gravity     Vec2D   // added to each particle's velocity each tick
+
+	Size, EndSize float32        // width of a particle (in pixels) when it's born, and when it dies
+	Colors        []GradientStop // color over a particle's life, from offset 0 at birth to 1 at death; nil for white
+
+	particles []particle
+	pending   float32 // part of a particle, from Rate, waiting to be spawned
+	rng       *rand.Rand
+
+	// reused to avoid allocating every frame
+	vs []ebiten.Vertex
+	is []uint32
+}
+
+type particle struct {
+	pos, vel Vec2D
+	age      int
+	life     int
+}
+
+// NewEmitter returns an emitter with nothing set to spawn. Particles are
+// random, but from a fixed seed, so the same events make the same particles
+// every run.
+func NewEmitter() *Emitter {
+	return &Emitter{
+		Life:    30,
+		Size:    2,
+		EndSize: 2,
+		rng:     rand.New(rand.NewSource(1)),
+	}
+}
+
+// Burst spawns n particles at once.
+func (e *Emitter) Burst(n int) {
+	for i := 0; i < n; i++ {
+		e.spawn()
+	}
+}
+
+// Len returns how many particles are alive.
+func (e *Emitter) Len() int {
+	return len(e.particles)
+}
+
+// spread returns a random value in [-s,s].
+func (e *Emitter) spread(s float32) float32 {
+	return (2*e.rng.Float32() - 1) * s
+}
+
+func (e *Emitter) spawn() {
+	angle := e.Angle + e.spread(e.Spread)
+	dir := Vec2D{X: float32(math.Cos(float64(angle))), Y: float32(math.Sin(float64(angle)))}
+	life := e.Life
+	if e.LifeSpread > 0 {
+		life += e.rng.Intn(2*e.LifeSpread+1) - e.LifeSpread
+	}
+	if life < 1 {
+		life = 1
+	}
+	e.particles = append(e.particles, particle{
+		pos:  e.Pos.Add(dir.Scale(e.Radius)),
+		vel:  dir.Scale(e.Speed + e.spread(e.SpeedSpread)),
+		life: life,
+	})
+}
+
+// Update moves every particle by a tick, removes the ones that have died, and
+// spawns new ones if Emitting.
+func (e *Emitter) Update() {
+	for i := 0; i < len(e.particles); {
+		p := &e.particles[i]
+		p.age++
+		if p.age >= p.life {
+			// order doesn't matter, so fill the gap with the last particle
+			last := len(e.particles) - 1
+			e.particles[i] = e.particles[last]
+			e.particles = e.particles[:last]
+			continue
+		}
+		p.vel = p.vel.Add(e.Gravity).Scale(1 - e.Drag)
+		p.pos = p.pos.Add(p.vel)
+		i++
+	}
+
+	if e.Emitting {
+		e.pending += e.Rate
+		for ; e.pending >= 1; e.pending-- {
+			e.spawn()
+		}
+	}
+}
+
+// Draw queues every particle to be drawn by r's next Flush.
+func (e *Emitter) Draw(r *Renderer) {
+	if len(e.particles) == 0 {
+		return
+	}
+	e.vs, e.is = e.vs[:0], e.is[:0]
+	for _, p := range e.particles {
+		t := float32(p.age) / float32(p.life)
+		half := lerp32(e.Size, e.EndSize, t) / 2
+		cr, cg, cb, ca := float32(1), float32(1), float32(1), float32(1)
+		if len(e.Colors) > 0 {
+			cr, cg, cb, ca = gradientAt(e.Colors, t)
+		}
+		if ca <= 0 || half <= 0 {
+			continue
+		}
+
+		base := uint32(len(e.vs))
+		for _, corner := range [4]Vec2D{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+			e.vs = append(e.vs, ebiten.Vertex{
+				DstX:   p.pos.X + corner.X*half,
+				DstY:   p.pos.Y + corner.Y*half,
+				ColorR: cr,
+				ColorG: cg,
+				ColorB: cb,
+				ColorA: ca,
+			})
+		}
+		e.is = append(e.is, base, base+1, base+2, base, base+2, base+3)
+	}
+	r.DrawTriangles(e.vs, e.is)
+}
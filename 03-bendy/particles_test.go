@@ -0,0 +1,93 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestEmitter_BurstLivesAndDies(t *testing.T) {
+	e := NewEmitter()
+	e.Pos = Vec2D{100, 100}
+	e.Radius = 10
+	e.Spread = math.Pi
+	e.Speed = 1
+	e.Life = 10
+	e.LifeSpread = 3
+
+	e.Burst(50)
+	if e.Len() != 50 {
+		t.Fatalf("expected 50 particles, got %d", e.Len())
+	}
+	for _, p := range e.particles {
+		if d := p.pos.Distance(e.Pos); math.Abs(float64(d-e.Radius)) > 1e-3 {
+			t.Errorf("expected particles to start %v from the emitter, got %v", e.Radius, d)
+		}
+		if p.life < 7 || p.life > 13 {
+			t.Errorf("expected a life of 10±3, got %d", p.life)
+		}
+	}
+
+	// particles move away from the emitter as they age
+	e.Update()
+	for _, p := range e.particles {
+		if d := p.pos.Distance(e.Pos); d <= e.Radius {
+			t.Errorf("expected particles to have moved outward, got %v from the emitter", d)
+		}
+	}
+
+	for i := 0; i < 12; i++ {
+		e.Update()
+	}
+	if e.Len() != 0 {
+		t.Errorf("expected every particle to have died, got %d left", e.Len())
+	}
+}
+
+func TestEmitter_Rate(t *testing.T) {
+	e := NewEmitter()
+	e.Rate = 0.5
+	e.Emitting = true
+	e.Life = 100
+
+	e.Update()
+	if e.Len() != 0 {
+		t.Errorf("expected half a particle to wait for the next tick, got %d", e.Len())
+	}
+	for i := 0; i < 9; i++ {
+		e.Update()
+	}
+	if e.Len() != 5 {
+		t.Errorf("expected 5 particles after 10 ticks, got %d", e.Len())
+	}
+
+	e.Emitting = false
+	e.Update()
+	if e.Len() != 5 {
+		t.Errorf("expected no more particles once stopped, got %d", e.Len())
+	}
+}
+
+func TestEmitter_DrawsColorOverLife(t *testing.T) {
+	e := NewEmitter()
+	e.Life = 4
+	e.Size = 4
+	e.EndSize = 0
+	e.Colors = []GradientStop{
+		{Offset: 0, Color: color.White},
+		{Offset: 1, Color: color.Transparent},
+	}
+	e.Burst(1)
+	e.Update() // a quarter of the way through its life
+
+	r := NewRenderer()
+	e.Draw(r)
+	b := r.batches[0]
+	if len(b.vs) != 4 || len(b.is) != 6 {
+		t.Fatalf("expected one quad, got %d vertices and %d indices", len(b.vs), len(b.is))
+	}
+	v := b.vs[0]
+	assertNear(t, "alpha", v.ColorA, 0.75)
+	assertNear(t, "premultiplied red", v.ColorR, 0.75)
+	assertNear(t, "width", b.vs[1].DstX-b.vs[0].DstX, 3)
+}
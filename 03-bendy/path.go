@@ -0,0 +1,322 @@
+package main
+
+import (
+	"math"
+)
+
+type pathOp int
+
+const (
+	pathMoveTo pathOp = iota
+	pathLineTo
+	pathQuadTo
+	pathCubicTo
+	pathArcTo
+	pathClose
+)
+
+type pathCmd struct {
+	op  pathOp
+	pts [3]Vec2D // control points, then the end point
+
+	// only used by pathArcTo
+	radii    Vec2D
+	rotation float32
+	largeArc bool
+	sweep    bool
+}
+
+// Path is an outline made of lines and curves, in a shape's local space. The
+// methods match those of SVG path data, so a path can be made of multiple
+// subpaths, each started by MoveTo and optionally ended by Close.
+type Path struct {
+	cmds    []pathCmd
+	start   Vec2D // start of the current subpath
+	cur     Vec2D
+	version uint64 // incremented on every change, so anything caching a flattened path knows to update
+}
+
+// Contour is a flattened subpath.
+type Contour struct {
+	Points []Vec2D
+	Closed bool
+}
+
+func (p *Path) add(cmd pathCmd) {
+	p.cmds = append(p.cmds, cmd)
+	p.version++
+}
+
+// Reset removes every subpath, so the path can be rebuilt without allocating.
+func (p *Path) Reset() {
+	p.cmds = p.cmds[:0]
+	p.start = Vec2D{}
+	p.cur = Vec2D{}
+	p.version++
+}
+
+func (p *Path) MoveTo(pt Vec2D) {
+	p.add(pathCmd{op: pathMoveTo, pts: [3]Vec2D{pt}})
+	p.start = pt
+	p.cur = pt
+}
+
+func (p *Path) LineTo(pt Vec2D) {
+	p.add(pathCmd{op: pathLineTo, pts: [3]Vec2D{pt}})
+	p.cur = pt
+}
+
+// QuadTo adds a quadratic Bézier curve with control point c, ending at pt.
+func (p *Path) QuadTo(c, pt Vec2D) {
+	p.add(pathCmd{op: pathQuadTo, pts: [3]Vec2D{c, pt}})
+	p.cur = pt
+}
+
+// CubicTo adds a cubic Bézier curve with control points c1 and c2, ending at pt.
+func (p *Path) CubicTo(c1, c2, pt Vec2D) {
+	p.add(pathCmd{op: pathCubicTo, pts: [3]Vec2D{c1, c2, pt}})
+	p.cur = pt
+}
+
+// ArcTo adds an elliptical arc ending at pt, with the same arguments as SVG's "A" command.
+// The ellipse has radii rx and ry, and is rotated by rotation radians. Of the four arcs
+// that fit, largeArc picks the longer ones, and sweep picks the one that travels in the
+// direction of positive angles (clockwise on screen). Radii that are too small to reach
+// pt are scaled up until they fit.
+func (p *Path) ArcTo(rx, ry, rotation float32, largeArc, sweep bool, pt Vec2D) {
+	p.add(pathCmd{
+		op:       pathArcTo,
+		pts:      [3]Vec2D{pt},
+		radii:    Vec2D{rx, ry},
+		rotation: rotation,
+		largeArc: largeArc,
+		sweep:    sweep,
+	})
+	p.cur = pt
+}
+
+// Close ends the current subpath with a line back to its start.
+func (p *Path) Close() {
+	p.add(pathCmd{op: pathClose})
+	p.cur = p.start
+}
+
+// Circle adds a closed subpath around center.
+func (p *Path) Circle(center Vec2D, radius float32) {
+	p.Ellipse(center, radius, radius)
+}
+
+// Ellipse adds a closed, axis-aligned elliptical subpath around center.
+func (p *Path) Ellipse(center Vec2D, rx, ry float32) {
+	right := Vec2D{center.X + rx, center.Y}
+	left := Vec2D{center.X - rx, center.Y}
+	p.MoveTo(right)
+	p.ArcTo(rx, ry, 0, false, true, left)
+	p.ArcTo(rx, ry, 0, false, true, right)
+	p.Close()
+}
+
+// Polygon adds a closed subpath through points.
+func (p *Path) Polygon(points []Vec2D) {
+	if len(points) == 0 {
+		return
+	}
+	p.MoveTo(points[0])
+	for _, pt := range points[1:] {
+		p.LineTo(pt)
+	}
+	p.Close()
+}
+
+// Flatten turns curves into line segments that stray no further than
+// tolerance from the true curve, and returns the resulting contours.
+func (p *Path) Flatten(tolerance float32) []Contour {
+	return p.AppendFlattened(nil, tolerance)
+}
+
+// AppendFlattened is like Flatten, but appends to (and reuses) contours.
+func (p *Path) AppendFlattened(contours []Contour, tolerance float32) []Contour {
+	if tolerance <= 0 {
+		tolerance = 0.25
+	}
+
+	var cur []Vec2D
+	var start, pos Vec2D
+	closed := false
+
+	endContour := func() {
+		if len(cur) > 1 {
+			if n := len(contours); n < cap(contours) {
+				// reuse the old backing array of this slot
+				contours = contours[:n+1]
+				contours[n].Points = append(contours[n].Points[:0], cur...)
+				contours[n].Closed = closed
+			} else {
+				contours = append(contours, Contour{Points: append([]Vec2D(nil), cur...), Closed: closed})
+			}
+		}
+		cur = cur[:0]
+		closed = false
+	}
+
+	contours = contours[:0]
+	for _, cmd := range p.cmds {
+		switch cmd.op {
+		case pathMoveTo:
+			endContour()
+			start = cmd.pts[0]
+			pos = start
+			cur = append(cur, pos)
+		case pathLineTo:
+			if len(cur) == 0 {
+				cur = append(cur, pos)
+			}
+			pos = cmd.pts[0]
+			cur = append(cur, pos)
+		case pathQuadTo:
+			if len(cur) == 0 {
+				cur = append(cur, pos)
+			}
+			cur = flattenQuad(cur, pos, cmd.pts[0], cmd.pts[1], tolerance)
+			pos = cmd.pts[1]
+		case pathCubicTo:
+			if len(cur) == 0 {
+				cur = append(cur, pos)
+			}
+			cur = flattenCubic(cur, pos, cmd.pts[0], cmd.pts[1], cmd.pts[2], tolerance)
+			pos = cmd.pts[2]
+		case pathArcTo:
+			if len(cur) == 0 {
+				cur = append(cur, pos)
+			}
+			cur = flattenArc(cur, pos, cmd, tolerance)
+			pos = cmd.pts[0]
+		case pathClose:
+			// drop the last point if it just repeats the first, since closing implies that edge
+			if n := len(cur); n > 1 && cur[n-1].ApproxEqual(cur[0], 1e-4) {
+				cur = cur[:n-1]
+			}
+			closed = true
+			endContour()
+			pos = start
+		}
+	}
+	endContour()
+
+	return contours
+}
+
+// flattenQuad appends points along the quadratic curve p0,p1,p2 (excluding p0).
+func flattenQuad(out []Vec2D, p0, p1, p2 Vec2D, tolerance float32) []Vec2D {
+	// uniform steps stray at most |p0 - 2p1 + p2| / (8n²) from the curve
+	dd := p0.Sub(p1.Scale(2)).Add(p2).Length()
+	n := int(math.Ceil(math.Sqrt(float64(dd / (8 * tolerance)))))
+	if n < 1 {
+		n = 1
+	}
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		mt := 1 - t
+		out = append(out, p0.Scale(mt*mt).Add(p1.Scale(2*mt*t)).Add(p2.Scale(t*t)))
+	}
+	return out
+}
+
+// flattenCubic appends points along the cubic curve p0,p1,p2,p3 (excluding p0).
+func flattenCubic(out []Vec2D, p0, p1, p2, p3 Vec2D, tolerance float32) []Vec2D {
+	// uniform steps stray at most 3/4 ∙ max(|p0 - 2p1 + p2|, |p1 - 2p2 + p3|) / n² from the curve
+	dd1 := p0.Sub(p1.Scale(2)).Add(p2).Length()
+	dd2 := p1.Sub(p2.Scale(2)).Add(p3).Length()
+	dd := dd1
+	if dd2 > dd {
+		dd = dd2
+	}
+	n := int(math.Ceil(math.Sqrt(float64(0.75 * dd / tolerance))))
+	if n < 1 {
+		n = 1
+	}
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		mt := 1 - t
+		out = append(out, p0.Scale(mt*mt*mt).
+			Add(p1.Scale(3*mt*mt*t)).
+			Add(p2.Scale(3*mt*t*t)).
+			Add(p3.Scale(t*t*t)))
+	}
+	return out
+}
+
+// flattenArc appends points along an SVG style arc from p0 (excluding p0).
+// See https://www.w3.org/TR/SVG11/implnote.html#ArcImplementationNotes
+func flattenArc(out []Vec2D, p0 Vec2D, cmd pathCmd, tolerance float32) []Vec2D {
+	p1 := cmd.pts[0]
+	rx := math.Abs(float64(cmd.radii.X))
+	ry := math.Abs(float64(cmd.radii.Y))
+	if rx == 0 || ry == 0 || p0.ApproxEqual(p1, 1e-6) {
+		return append(out, p1)
+	}
+
+	sinPhi, cosPhi := math.Sincos(float64(cmd.rotation))
+
+	// step 1: move to a space where the ellipse is axis aligned, and the midpoint of p0,p1 is the origin
+	dx := float64(p0.X-p1.X) / 2
+	dy := float64(p0.Y-p1.Y) / 2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+
+	// scale up radii that are too small to reach
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		s := math.Sqrt(l)
+		rx *= s
+		ry *= s
+	}
+
+	// step 2: find the center (in the aligned space)
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := 0.0
+	if num > 0 && den > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if cmd.largeArc == cmd.sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+
+	// step 3: back to the original space
+	cx := cosPhi*cx1 - sinPhi*cy1 + float64(p0.X+p1.X)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + float64(p0.Y+p1.Y)/2
+
+	// step 4: start and sweep angles
+	theta1 := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	theta2 := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx)
+	dTheta := theta2 - theta1
+	if cmd.sweep && dTheta < 0 {
+		dTheta += 2 * math.Pi
+	} else if !cmd.sweep && dTheta > 0 {
+		dTheta -= 2 * math.Pi
+	}
+
+	// each step of angle a strays at most r∙(1 - cos(a/2)) from the curve
+	r := math.Max(rx, ry)
+	step := math.Pi / 2
+	if float64(tolerance) < r {
+		step = 2 * math.Acos(1-float64(tolerance)/r)
+	}
+	n := int(math.Ceil(math.Abs(dTheta) / step))
+	if n < 1 {
+		n = 1
+	}
+
+	for i := 1; i < n; i++ {
+		theta := theta1 + dTheta*float64(i)/float64(n)
+		sinT, cosT := math.Sincos(theta)
+		out = append(out, Vec2D{
+			float32(cx + rx*cosT*cosPhi - ry*sinT*sinPhi),
+			float32(cy + rx*cosT*sinPhi + ry*sinT*cosPhi),
+		})
+	}
+	// end exactly on p1, so contours close cleanly
+	return append(out, p1)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPath_FlattenLines(t *testing.T) {
+	var p Path
+	p.MoveTo(Vec2D{0, 0})
+	p.LineTo(Vec2D{10, 0})
+	p.LineTo(Vec2D{10, 10})
+	p.Close()
+	p.MoveTo(Vec2D{20, 0})
+	p.LineTo(Vec2D{30, 0})
+
+	cs := p.Flatten(0.25)
+	if len(cs) != 2 {
+		t.Fatalf("expected 2 contours, got %d", len(cs))
+	}
+	if !cs[0].Closed || len(cs[0].Points) != 3 {
+		t.Errorf("expected closed triangle, got %+v", cs[0])
+	}
+	if cs[1].Closed || len(cs[1].Points) != 2 {
+		t.Errorf("expected open line, got %+v", cs[1])
+	}
+}
+
+func TestPath_FlattenCurves(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Build func(p *Path)
+		// every flattened point must be on the curve, as measured by this
+		OnCurve func(v Vec2D) bool
+		End     Vec2D
+	}{
+		{
+			"quad",
+			func(p *Path) {
+				p.MoveTo(Vec2D{0, 0})
+				p.QuadTo(Vec2D{50, 100}, Vec2D{100, 0})
+			},
+			// y = 2x - x²/50 for this curve
+			func(v Vec2D) bool { return math.Abs(float64(v.Y-(2*v.X-v.X*v.X/50))) < 0.01 },
+			Vec2D{100, 0},
+		},
+		{
+			"cubic",
+			func(p *Path) {
+				p.MoveTo(Vec2D{0, 0})
+				p.CubicTo(Vec2D{0, 100}, Vec2D{100, 100}, Vec2D{100, 0})
+			},
+			// this curve peaks at y = 75 halfway along, and stays inside its control points
+			func(v Vec2D) bool { return v.X >= 0 && v.X <= 100 && v.Y >= 0 && v.Y <= 75.01 },
+			Vec2D{100, 0},
+		},
+		{
+			"half circle",
+			func(p *Path) {
+				p.MoveTo(Vec2D{50, 0})
+				p.ArcTo(50, 50, 0, false, true, Vec2D{-50, 0})
+			},
+			func(v Vec2D) bool { return math.Abs(float64(v.Length()-50)) < 0.01 && v.Y >= -0.01 },
+			Vec2D{-50, 0},
+		},
+		{
+			"radii too small",
+			func(p *Path) {
+				p.MoveTo(Vec2D{50, 0})
+				p.ArcTo(1, 1, 0, false, false, Vec2D{-50, 0})
+			},
+			func(v Vec2D) bool { return math.Abs(float64(v.Length()-50)) < 0.01 && v.Y <= 0.01 },
+			Vec2D{-50, 0},
+		},
+		{
+			"rotated ellipse",
+			func(p *Path) {
+				p.MoveTo(Vec2D{0, -20})
+				p.ArcTo(20, 10, math.Pi/2, true, true, Vec2D{0, 20})
+			},
+			// rotated 90 degrees, the x radius lines up with the y axis
+			func(v Vec2D) bool {
+				return math.Abs(float64(v.X*v.X/100+v.Y*v.Y/400-1)) < 0.01
+			},
+			Vec2D{0, 20},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var p Path
+			tc.Build(&p)
+			cs := p.Flatten(0.1)
+			if len(cs) != 1 {
+				t.Fatalf("expected 1 contour, got %d", len(cs))
+			}
+			pts := cs[0].Points
+			if len(pts) < 4 {
+				t.Errorf("expected the curve to be split into several points, got %d", len(pts))
+			}
+			for _, v := range pts {
+				if !tc.OnCurve(v) {
+					t.Errorf("point %v is not on the curve", v)
+				}
+			}
+			assertVec(t, pts[len(pts)-1], tc.End)
+		})
+	}
+}
+
+func TestPath_FlattenTolerance(t *testing.T) {
+	var p Path
+	p.Circle(Vec2D{0, 0}, 100)
+
+	coarse := p.Flatten(1)[0]
+	fine := p.Flatten(0.01)[0]
+	if !coarse.Closed || !fine.Closed {
+		t.Fatalf("circle should be closed")
+	}
+	if len(fine.Points) <= len(coarse.Points) {
+		t.Errorf("expected a finer tolerance to use more points (%d <= %d)", len(fine.Points), len(coarse.Points))
+	}
+
+	// the midpoint of each edge is where a polygon strays furthest from its circle
+	for _, tc := range []struct {
+		Tol float32
+		Pts []Vec2D
+	}{{1, coarse.Points}, {0.01, fine.Points}} {
+		for i := range tc.Pts {
+			mid := tc.Pts[i].Lerp(tc.Pts[(i+1)%len(tc.Pts)], 0.5)
+			if d := 100 - mid.Length(); d > tc.Tol*1.01 {
+				t.Errorf("tolerance %v: edge %d strays %v from the circle", tc.Tol, i, d)
+				break
+			}
+		}
+	}
+}
+
+func TestPath_AppendFlattenedReuses(t *testing.T) {
+	var p Path
+	p.Circle(Vec2D{0, 0}, 10)
+	p.Circle(Vec2D{30, 0}, 10)
+
+	cs := p.Flatten(0.1)
+	first := &cs[1].Points[0]
+	cs = p.AppendFlattened(cs, 0.1)
+	if len(cs) != 2 || &cs[1].Points[0] != first {
+		t.Errorf("expected contours to be reused")
+	}
+}
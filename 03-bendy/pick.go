@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// CursorPos returns the mouse position in screen space, which is the world space that shapes are drawn in.
+func CursorPos() Vec2D {
+	x, y := ebiten.CursorPosition()
+	return Vec2D{X: float32(x), Y: float32(y)}
+}
+
+// ShapeUnderCursor returns the top-most of shapes under the mouse, or nil if there isn't one.
+func ShapeUnderCursor(shapes []*Shape) *Shape {
+	return PickShape(shapes, CursorPos())
+}
+
+// ClickedShape returns the top-most of shapes under the mouse if the left
+// button was pressed this tick, or nil otherwise.
+func ClickedShape(shapes []*Shape) *Shape {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return nil
+	}
+	return ShapeUnderCursor(shapes)
+}
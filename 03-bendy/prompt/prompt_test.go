@@ -0,0 +1,127 @@
+package prompt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrompter_IntRange(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Input  string
+		Expect int
+		Err    error
+	}{
+		{"simple", "3\n", 3, nil},
+		{"whitespace", "  7 \r\n", 7, nil},
+		{"no trailing newline", "5", 5, nil},
+		{"min", "0\n", 0, nil},
+		{"max", "15\n", 15, nil},
+		{"retry after garbage", "abc\n4\n", 4, nil},
+		{"retry after out of range", "16\n-1\n9\n", 9, nil},
+		{"retry after blank", "\n2\n", 2, nil},
+		{"closed", "", 0, ErrClosed},
+		{"closed after garbage", "abc\n", 0, ErrClosed},
+		{"cancel", "q\n", 0, ErrCanceled},
+		{"cancel long", "QUIT\n", 0, ErrCanceled},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var out strings.Builder
+			p := New(strings.NewReader(tc.Input), &out)
+			n, err := p.IntRange("Pick one", 0, 15)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected error %v, got %v", tc.Err, err)
+			}
+			if n != tc.Expect {
+				t.Errorf("expected %d, got %d", tc.Expect, n)
+			}
+			if !strings.HasPrefix(out.String(), "Pick one [0-15]: ") {
+				t.Errorf("unexpected prompt: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestPrompter_IntRangeDefault(t *testing.T) {
+	var out strings.Builder
+	p := New(strings.NewReader("\n"), &out)
+	n, err := p.IntRangeDefault("Channel?", 0, 15, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 9 {
+		t.Errorf("expected default 9, got %d", n)
+	}
+	if out.String() != "Channel [0-15] (default 9)? " {
+		t.Errorf("unexpected prompt: %q", out.String())
+	}
+}
+
+func TestPrompter_KeepsBufferedInput(t *testing.T) {
+	// all answers arrive in a single read, so a new bufio.Reader per prompt would lose them
+	p := New(strings.NewReader("1\nx\n2\n3\n"), &strings.Builder{})
+	for i, expect := range []int{1, 2, 3} {
+		n, err := p.Int("Number")
+		if err != nil {
+			t.Fatalf("prompt %d: %v", i, err)
+		}
+		if n != expect {
+			t.Errorf("prompt %d: expected %d, got %d", i, expect, n)
+		}
+	}
+}
+
+func TestPrompter_Choose(t *testing.T) {
+	var out strings.Builder
+	p := New(strings.NewReader("1\n"), &out)
+	n, err := p.Choose("Choose your MIDI device", []string{"Arturia", "nanoKONTROL", "MIDImix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1, got %d", n)
+	}
+	expect := " 0: Arturia\n 1: nanoKONTROL\n 2: MIDImix\nChoose your MIDI device [0-2]: "
+	if out.String() != expect {
+		t.Errorf("unexpected output:\n%q\nexpected:\n%q", out.String(), expect)
+	}
+
+	_, err = New(strings.NewReader("0\n"), &out).Choose("Choose", nil)
+	if err == nil {
+		t.Errorf("expected error when choosing from nothing")
+	}
+}
+
+func TestPrompter_YesNo(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Def    bool
+		Expect bool
+		Err    error
+	}{
+		{"y\n", false, true, nil},
+		{"Yes\n", false, true, nil},
+		{"n\n", true, false, nil},
+		{"NO\n", true, false, nil},
+		{"\n", true, true, nil},
+		{"\n", false, false, nil},
+		{"maybe\ny\n", false, true, nil},
+		{"", true, false, ErrClosed},
+		{"q\n", true, false, ErrCanceled},
+	}
+
+	for _, tc := range cases {
+		p := New(strings.NewReader(tc.Input), &strings.Builder{})
+		b, err := p.YesNo("Save?", tc.Def)
+		if !errors.Is(err, tc.Err) {
+			t.Errorf("input %q: expected error %v, got %v", tc.Input, tc.Err, err)
+			continue
+		}
+		if b != tc.Expect {
+			t.Errorf("input %q: expected %t, got %t", tc.Input, tc.Expect, b)
+		}
+	}
+}
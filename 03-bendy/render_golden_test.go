@@ -0,0 +1,81 @@
+//go:build golden
+
+// These tests draw with the GPU, and compare the results against golden
+// images in testdata/golden. They need a display, so are behind the "golden"
+// build tag. On a machine without one, use a virtual X server and software GL:
+//
+//	make golden (or: xvfb-run go test -tags golden -run Golden .)
+//
+// After changing what's drawn on purpose, rewrite the golden images with
+// make golden-update, and look over the new ones before committing them.
+
+package main
+
+import (
+	"image"
+	"os"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestMain(m *testing.M) {
+	// ebiten can only draw and read pixels while a game is running, so the
+	// tests are run from inside its first Update
+	g := &testGame{m: m, code: 1}
+	ebiten.SetRunnableOnUnfocused(true)
+	if err := ebiten.RunGameWithOptions(g, &ebiten.RunGameOptions{InitUnfocused: true, SkipTaskbar: true}); err != nil {
+		panic(err)
+	}
+	os.Exit(g.code)
+}
+
+type testGame struct {
+	m    *testing.M
+	code int
+}
+
+func (g *testGame) Update() error {
+	g.code = g.m.Run()
+	return ebiten.Termination
+}
+
+func (*testGame) Draw(*ebiten.Image) {}
+
+func (*testGame) Layout(int, int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+// renderScene runs ticks updates of scene, then draws it offscreen.
+func renderScene(t *testing.T, scene Scene, ticks int) *image.RGBA {
+	t.Helper()
+	for i := 0; i < ticks; i++ {
+		if err := scene.Update(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	img := ebiten.NewImage(screenWidth, screenHeight)
+	defer img.Dispose()
+	scene.Draw(nil, img)
+	return ReadImage(img)
+}
+
+func TestGameScene_Golden(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Knobs [KNOB_COUNT]int
+	}{
+		{"dial", [KNOB_COUNT]int{}},
+		{"dial_turned", [KNOB_COUNT]int{32}},
+		{"dial_deformed", [KNOB_COUNT]int{0, 64, 64, 64}},
+		{"dial_click", [KNOB_COUNT]int{100}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			scene := NewGameScene(NewFixedMidiMgr(tc.Knobs), nil, nil, nil)
+			scene.rotGoal = 100
+			assertGolden(t, tc.Name, renderScene(t, scene, 60))
+		})
+	}
+}
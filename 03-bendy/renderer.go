@@ -0,0 +1,156 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// maxBatchVertices is the most vertices that 16-bit indices can address.
+	maxBatchVertices = math.MaxUint16 + 1
+
+	// maxBatchIndices is the most indices one DrawTriangles call can take.
+	maxBatchIndices = ebiten.MaxIndicesCount
+)
+
+// Renderer collects the triangles of many shapes into shared vertex and index
+// buffers, then draws them all with as few DrawTriangles calls as possible.
+// A new batch (and draw call) is only started when the current one is too big
+// for 16-bit indices, and meshes too big for one batch are split across as
+// many as they need. Buffers are kept from frame to frame, so once they've
+// grown to fit a scene, queueing and flushing don't allocate.
+type Renderer struct {
+	batches []rendererBatch
+	used    int // number of batches holding triangles this frame
+	op      ebiten.DrawTrianglesOptions
+
+	// reused to avoid allocating every frame
+	vs    []ebiten.Vertex
+	is    []uint32
+	remap []int32
+
+	drawCalls int // made by the last Flush
+}
+
+type rendererBatch struct {
+	vs []ebiten.Vertex
+	is []uint16
+}
+
+func NewRenderer() *Renderer {
+	r := &Renderer{}
+	r.op.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+	r.op.AntiAlias = true
+	return r
+}
+
+// batch returns the batch that new triangles are added to.
+func (r *Renderer) batch() *rendererBatch {
+	if r.used == 0 {
+		r.nextBatch()
+	}
+	return &r.batches[r.used-1]
+}
+
+// nextBatch starts a new batch, reusing the buffers of an old one if possible.
+func (r *Renderer) nextBatch() *rendererBatch {
+	if r.used == len(r.batches) {
+		r.batches = append(r.batches, rendererBatch{})
+	}
+	r.used++
+	b := &r.batches[r.used-1]
+	b.vs, b.is = b.vs[:0], b.is[:0]
+	return b
+}
+
+// DrawShape queues s to be drawn by the next Flush, on top of anything already queued.
+func (r *Renderer) DrawShape(s *Shape) {
+	r.vs, r.is = s.AppendTriangles(r.vs[:0], r.is[:0])
+	r.DrawTriangles(r.vs, r.is)
+}
+
+// DrawTriangles queues triangles whose vertices have their (premultiplied)
+// colors set, to be drawn by the next Flush. Indices are relative to vs.
+func (r *Renderer) DrawTriangles(vs []ebiten.Vertex, is []uint32) {
+	b := r.batch()
+	if len(b.vs)+len(vs) > maxBatchVertices || len(b.is)+len(is) > maxBatchIndices {
+		if len(vs) > maxBatchVertices || len(is) > maxBatchIndices {
+			r.split(vs, is)
+			return
+		}
+		b = r.nextBatch()
+	}
+
+	base := uint16(len(b.vs))
+	b.vs = append(b.vs, vs...)
+	for _, i := range is {
+		b.is = append(b.is, base+uint16(i))
+	}
+}
+
+// split queues a mesh that's too big for one batch, one triangle at a time,
+// starting a new batch whenever the current one fills up. Vertices are copied
+// into each batch the first time one of its triangles uses them.
+func (r *Renderer) split(vs []ebiten.Vertex, is []uint32) {
+	// remap holds where each of vs was copied to in the current batch, or -1
+	for len(r.remap) < len(vs) {
+		r.remap = append(r.remap, 0)
+	}
+	remap := r.remap[:len(vs)]
+	for i := range remap {
+		remap[i] = -1
+	}
+
+	b := r.batch()
+	for t := 0; t+2 < len(is); t += 3 {
+		tri := is[t : t+3]
+		added := 0
+		for _, i := range tri {
+			if remap[i] < 0 {
+				added++
+			}
+		}
+		if len(b.vs)+added > maxBatchVertices || len(b.is)+3 > maxBatchIndices {
+			b = r.nextBatch()
+			for i := range remap {
+				remap[i] = -1
+			}
+		}
+		for _, i := range tri {
+			if remap[i] < 0 {
+				remap[i] = int32(len(b.vs))
+				b.vs = append(b.vs, vs[i])
+			}
+			b.is = append(b.is, uint16(remap[i]))
+		}
+	}
+}
+
+// Flush draws everything queued since the last Flush onto dst, then empties the queue.
+func (r *Renderer) Flush(dst *ebiten.Image) {
+	r.drawCalls = 0
+	for i := 0; i < r.used; i++ {
+		b := &r.batches[i]
+		if len(b.is) == 0 {
+			continue
+		}
+		for j := range b.vs {
+			b.vs[j].SrcX = 1
+			b.vs[j].SrcY = 1
+		}
+		dst.DrawTriangles(b.vs, b.is, whiteSubImage, &r.op)
+		r.drawCalls++
+	}
+	r.Reset()
+}
+
+// Reset empties the queue without drawing anything.
+func (r *Renderer) Reset() {
+	r.used = 0
+}
+
+// DrawCalls returns how many DrawTriangles calls the last Flush made.
+func (r *Renderer) DrawCalls() int {
+	return r.drawCalls
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// benchShapes returns n filled and stroked circles, spread across the screen.
+func benchShapes(n int) []*Shape {
+	shapes := make([]*Shape, n)
+	for i := range shapes {
+		var p Path
+		p.Circle(Vec2D{}, 20)
+		s := NewPathShape(&p, color.White, 2)
+		s.Fill = SolidPaint{Color: color.Gray{Y: 0x80}}
+		s.Xfm.SetPos(Vec2D{X: float32(i%20) * 30, Y: float32(i/20) * 30})
+		shapes[i] = s
+	}
+	return shapes
+}
+
+// triangles returns a mesh with n vertices, whose indices are all in range.
+func triangles(n int) ([]ebiten.Vertex, []uint32) {
+	vs := make([]ebiten.Vertex, n)
+	is := make([]uint32, 0, n)
+	for i := 0; i+2 < n; i += 3 {
+		is = append(is, uint32(i), uint32(i+1), uint32(i+2))
+	}
+	return vs, is
+}
+
+func TestRenderer_OneBatch(t *testing.T) {
+	r := NewRenderer()
+	shapes := benchShapes(50)
+	for _, s := range shapes {
+		r.DrawShape(s)
+	}
+	if r.used != 1 {
+		t.Fatalf("expected 1 batch, got %d", r.used)
+	}
+
+	// the last shape's indices were offset to point at its own vertices
+	b := r.batches[0]
+	vs, is := shapes[len(shapes)-1].AppendTriangles(nil, nil)
+	offset := uint32(len(b.vs) - len(vs))
+	last := b.is[len(b.is)-len(is):]
+	for i := range is {
+		if uint32(last[i]) != is[i]+offset {
+			t.Fatalf("index %d: expected %d, got %d", i, is[i]+offset, last[i])
+		}
+	}
+}
+
+func TestRenderer_SplitsBatches(t *testing.T) {
+	r := NewRenderer()
+	vs, is := triangles(30000)
+	for i := 0; i < 3; i++ {
+		r.DrawTriangles(vs, is)
+	}
+	// two meshes fit in 65536 vertices, the third starts a new batch
+	if r.used != 2 {
+		t.Fatalf("expected 2 batches, got %d", r.used)
+	}
+	if len(r.batches[0].vs) != 60000 || len(r.batches[1].vs) != 30000 {
+		t.Fatalf("unexpected batch sizes %d and %d", len(r.batches[0].vs), len(r.batches[1].vs))
+	}
+	for i, idx := range r.batches[1].is {
+		if uint32(idx) != is[i] {
+			t.Fatalf("index %d wasn't moved back to the start of the batch: expected %d, got %d", i, is[i], idx)
+		}
+	}
+
+	// batches are reused after a reset
+	r.Reset()
+	r.DrawTriangles(vs, is)
+	if r.used != 1 || len(r.batches) != 2 || len(r.batches[0].vs) != 30000 {
+		t.Errorf("expected the first batch to be reused")
+	}
+}
+
+func TestRenderer_SplitsLargeMeshes(t *testing.T) {
+	// a big gradient is filled with lots of small triangles
+	big := NewShape(square(0, 0, 4000), nil, 0)
+	big.Fill = LinearGradient{End: Vec2D{4000, 0}, Stops: []GradientStop{{0, color.White}, {1, color.Black}}}
+
+	// a detailed outline has a long stroke
+	detailed := NewShape(nil, color.White, 2)
+	for i := 0; i < 20000; i++ {
+		a := float64(i) * twoPi / 20000
+		detailed.Points = append(detailed.Points, Vec2D{float32(1000 * math.Cos(a)), float32(1000 * math.Sin(a))})
+	}
+
+	for _, s := range []*Shape{big, detailed} {
+		vs, is := s.AppendTriangles(nil, nil)
+		if len(vs) <= maxBatchVertices {
+			t.Fatalf("expected more than %d vertices, got %d", maxBatchVertices, len(vs))
+		}
+		for _, i := range is {
+			if int(i) >= len(vs) {
+				t.Fatalf("index %d is out of range", i)
+			}
+		}
+
+		r := NewRenderer()
+		// after a small mesh, so the split starts partway through a batch
+		r.DrawTriangles(triangles(30))
+		r.DrawShape(s)
+		if r.used < 2 {
+			t.Fatalf("expected more than one batch, got %d", r.used)
+		}
+
+		// every triangle is drawn, and they cover the same area
+		var indices int
+		var area float32
+		for _, b := range r.batches[:r.used] {
+			if len(b.vs) > maxBatchVertices || len(b.is) > maxBatchIndices {
+				t.Errorf("batch is too big: %d vertices and %d indices", len(b.vs), len(b.is))
+			}
+			for _, i := range b.is {
+				if int(i) >= len(b.vs) {
+					t.Fatalf("index %d is out of range", i)
+				}
+			}
+			indices += len(b.is)
+			area += meshArea(b.vs, b.is)
+		}
+		if indices != len(is)+30 {
+			t.Errorf("expected %d indices, got %d", len(is)+30, indices)
+		}
+		if expected := meshArea(vs, is); math.Abs(float64(area-expected)) > 1e-3*float64(expected) {
+			t.Errorf("expected area %v, got %v", expected, area)
+		}
+	}
+}
+
+func TestRenderer_NoAllocs(t *testing.T) {
+	r := NewRenderer()
+	vs, is := triangles(3000)
+	frame := func() {
+		for i := 0; i < 30; i++ {
+			r.DrawTriangles(vs, is)
+		}
+		r.Reset()
+	}
+	frame() // grow the buffers
+
+	if allocs := testing.AllocsPerRun(10, frame); allocs != 0 {
+		t.Errorf("expected no allocations per frame once warmed up, got %v", allocs)
+	}
+}
+
+// BenchmarkRenderer_Shapes measures queueing 100 shapes per frame (after the
+// first, which tessellates them). Run with -benchmem to see allocations per frame.
+func BenchmarkRenderer_Shapes(b *testing.B) {
+	r := NewRenderer()
+	shapes := benchShapes(100)
+	for _, s := range shapes {
+		r.DrawShape(s)
+	}
+	r.Reset()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, s := range shapes {
+			r.DrawShape(s)
+		}
+		r.Reset()
+	}
+}
+
+// BenchmarkShape_Draw is the unbatched equivalent of BenchmarkRenderer_Shapes,
+// building each shape's triangles in its own buffers.
+func BenchmarkShape_Draw(b *testing.B) {
+	shapes := benchShapes(100)
+	vs := make([][]ebiten.Vertex, len(shapes))
+	is := make([][]uint32, len(shapes))
+	frame := func() {
+		for i, s := range shapes {
+			vs[i], is[i] = s.AppendTriangles(vs[i][:0], is[i][:0])
+		}
+	}
+	frame()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		frame()
+	}
+}
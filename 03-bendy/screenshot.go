@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// RunScreenshot renders the game scene, with the knobs and goal given on the
+// command line, to a PNG file. It still needs a window (ebiten has no
+// headless mode), so on a machine without a display, run it under a virtual
+// X server with software GL, e.g. `xvfb-run go run . -screenshot out.png`.
+func RunScreenshot(f Flags) error {
+	knobs, err := parseKnobs(f.Knobs)
+	if err != nil {
+		return err
+	}
+	dialSVG, err := loadDial(f.DialPath)
+	if err != nil {
+		return err
+	}
+	fnt, err := loadFont(f.FontPath)
+	if err != nil {
+		return err
+	}
+	// there's no config file in this mode, so only the built-in themes are available
+	theme, err := Config{}.SelectTheme(f.Theme)
+	if err != nil {
+		return err
+	}
+
+	scene := NewGameScene(NewFixedMidiMgr(knobs), nil, dialSVG, fnt)
+	scene.SetThemes(BuiltinThemes(), theme.Name)
+	if f.Goal >= 0 {
+		scene.rotGoal = f.Goal
+	}
+	mgr := NewSceneMgr()
+	mgr.AddScene(SceneGame, scene)
+	mgr.SwitchScene(SceneGame)
+
+	g := &screenshotGame{Game: mgr, ticks: f.Ticks, path: f.ScreenshotPath}
+	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowTitle("spin click (screenshot)")
+	ebiten.SetRunnableOnUnfocused(true)
+	if err := ebiten.RunGameWithOptions(g, &ebiten.RunGameOptions{InitUnfocused: true, SkipTaskbar: true}); err != nil {
+		return err
+	}
+	return g.err
+}
+
+// parseKnobs parses comma separated knob values, e.g. "64,0,127". Knobs that
+// aren't given are left at 0.
+func parseKnobs(s string) ([KNOB_COUNT]int, error) {
+	var knobs [KNOB_COUNT]int
+	if len(strings.TrimSpace(s)) == 0 {
+		return knobs, nil
+	}
+
+	fields := strings.Split(s, ",")
+	if len(fields) > KNOB_COUNT {
+		return knobs, fmt.Errorf("parseKnobs(%s): expected at most %d values", s, KNOB_COUNT)
+	}
+	for i, field := range fields {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return knobs, fmt.Errorf("parseKnobs(%s): %w", s, err)
+		}
+		if v < 0 || v > 127 {
+			return knobs, fmt.Errorf("parseKnobs(%s): knob %d is %d, expected 0-127", s, i, v)
+		}
+		knobs[i] = v
+	}
+	return knobs, nil
+}
+
+// screenshotGame runs a game for a number of ticks, then saves the next frame
+// it draws to a PNG file and quits.
+type screenshotGame struct {
+	ebiten.Game
+	ticks int // left to run before saving
+	path  string
+
+	offscreen *ebiten.Image
+	saved     bool
+	err       error
+}
+
+func (g *screenshotGame) Update() error {
+	if g.saved {
+		return ebiten.Termination
+	}
+	if g.ticks <= 0 {
+		return nil
+	}
+	g.ticks--
+	return g.Game.Update()
+}
+
+func (g *screenshotGame) Draw(screen *ebiten.Image) {
+	if g.ticks > 0 || g.saved {
+		g.Game.Draw(screen)
+		return
+	}
+
+	// draw somewhere that isn't scaled to fit the window
+	if g.offscreen == nil {
+		g.offscreen = ebiten.NewImage(screenWidth, screenHeight)
+	}
+	g.offscreen.Clear()
+	g.Game.Draw(g.offscreen)
+	g.err = SavePNG(g.path, ReadImage(g.offscreen))
+	g.saved = true
+	screen.DrawImage(g.offscreen, nil)
+}
+
+// ReadImage copies img's pixels into an image.RGBA. Both use premultiplied
+// alpha, so no conversion is needed. It can only be called while the game is
+// running.
+func ReadImage(img *ebiten.Image) *image.RGBA {
+	rgba := image.NewRGBA(img.Bounds())
+	img.ReadPixels(rgba.Pix)
+	return rgba
+}
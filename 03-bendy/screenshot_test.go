@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestParseKnobs(t *testing.T) {
+	cases := []struct {
+		Name   string
+		S      string
+		Expect [KNOB_COUNT]int
+		Err    bool
+	}{
+		{"empty", "", [KNOB_COUNT]int{}, false},
+		{"all", "1,2,3,127", [KNOB_COUNT]int{1, 2, 3, 127}, false},
+		{"some", " 64 , 5", [KNOB_COUNT]int{64, 5, 0, 0}, false},
+		{"too many", "1,2,3,4,5", [KNOB_COUNT]int{}, true},
+		{"out of range", "128", [KNOB_COUNT]int{}, true},
+		{"not a number", "a", [KNOB_COUNT]int{}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			knobs, err := parseKnobs(tc.S)
+			if tc.Err {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if knobs != tc.Expect {
+				t.Errorf("expected %v, got %v", tc.Expect, knobs)
+			}
+		})
+	}
+}
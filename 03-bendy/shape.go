@@ -6,13 +6,300 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// Shape is an outline that can be filled and stroked. Shapes are Nodes, so
+// they can be parented to each other to build composite objects (e.g. a
+// needle on a dial).
+//
+// A shape's triangles are cached in local space, so moving, rotating or
+// scaling it only transforms them. Changes to Points (assigning a new slice),
+// Path, StrokeWidth, FillRule, and the colors of solid fills and strokes are
+// noticed automatically; after any other change (editing Points in place, or
+// changing Deformers, Stroke, or a gradient Fill), call Invalidate.
 type Shape struct {
-	Points      []Vec2D
-	Xfm         Transform2D
-	Color       color.Color
-	StrokeWidth float32
+	Node
+
+	Points      []Vec2D     // closed outline in local space; ignored if Path is set
+	Path        *Path       // outline with curves and multiple subpaths, in local space
+	Color       color.Color // stroke color
+	StrokeWidth float32     // in pixels, unaffected by scale; 0 for no stroke
+	Stroke      StrokeStyle
+	Fill        Paint // nil for no fill
+	FillRule    FillRule
+	Deformers   []Deformer // applied in order to the flattened outline
+
+	// The outline is flattened and deformed into contours, which are only
+	// redone when it changes, or when the shape's scale changes enough to
+	// need more or fewer points to stay smooth.
+	contours      []Contour // flattened and deformed
+	contoursValid bool
+	flat          []Contour // Path, flattened
+	flatPath      *Path
+	flatVersion   uint64
+	flatPoints    []Vec2D // Points, as last seen
+	flatTolerance float32
+	pointsContour [1]Contour
+	deformed      []Contour
+
+	// Triangles built from contours, in local space. Fill triangles are
+	// right under any transform, but a stroke is always StrokeWidth pixels
+	// wide, so its triangles are only cached while the shape's world matrix
+	// scales uniformly, and are rebuilt if that scale changes.
+	fillVs       []ebiten.Vertex
+	fillIs       []uint32
+	fillValid    bool
+	fillRule     FillRule
+	fillGradient bool // whether fillVs have their colors set
+	strokeVs     []ebiten.Vertex
+	strokeIs     []uint32
+	strokeScale  float32 // scale the stroke was built for, or 0 if it needs rebuilding
+	strokeWidth  float32
+	strokeDashes []float32
+
+	// reused to avoid allocating every frame
+	world        []Contour
+	fillContours [][]Vec2D
+	renderer     *Renderer // used by Draw
+}
+
+func NewShape(points []Vec2D, clr color.Color, strokeWidth float32) *Shape {
+	s := &Shape{
+		Points:      points,
+		Color:       clr,
+		StrokeWidth: strokeWidth,
+	}
+	s.Node.Init()
+	return s
+}
+
+func NewPathShape(path *Path, clr color.Color, strokeWidth float32) *Shape {
+	s := NewShape(nil, clr, strokeWidth)
+	s.Path = path
+	return s
+}
+
+const (
+	// pathTolerance is how far (in pixels) flattened curves may stray from the true curve.
+	pathTolerance = 0.25
+
+	// deformStep is the longest edge (in pixels) an outline is split into before being deformed.
+	deformStep = 4
+
+	// gradientStep is the longest edge (in pixels) of the triangles used to approximate a gradient fill.
+	gradientStep = 8
+)
+
+// Invalidate tells the shape that something it can't notice by itself has
+// changed (see Shape), so its triangles need to be rebuilt.
+func (s *Shape) Invalidate() {
+	s.contoursValid = false
+}
+
+// localContours returns the shape's outline in local space, flattened with
+// enough points to look smooth after being transformed by m, then deformed.
+func (s *Shape) localContours(m Matrix3x3) []Contour {
+	scale := m.MaxScale()
+	if scale == 0 {
+		return nil
+	}
+
+	tol := pathTolerance / scale
+	if tol > s.flatTolerance*1.5 || tol < s.flatTolerance/1.5 {
+		// only curves and deformers need more points when scaled up, but
+		// fills with gradients need more triangles too
+		if s.Path != nil || len(s.Deformers) > 0 || isGradient(s.Fill) {
+			s.contoursValid = false
+		}
+	}
+	if s.Path == nil {
+		if !samePoints(s.flatPoints, s.Points) {
+			s.flatPoints = s.Points
+			s.contoursValid = false
+		}
+	} else if s.flatPath != s.Path || s.flatVersion != s.Path.version {
+		s.contoursValid = false
+	}
+	if s.contoursValid {
+		return s.contours
+	}
+
+	s.contoursValid = true
+	s.flatTolerance = tol
+	s.fillValid = false
+	s.strokeScale = 0
+
+	s.contours = s.flatContours(tol)
+	if len(s.Deformers) == 0 {
+		return s.contours
+	}
+
+	for len(s.deformed) < len(s.contours) {
+		s.deformed = append(s.deformed, Contour{})
+	}
+	s.deformed = s.deformed[:len(s.contours)]
+	for i, c := range s.contours {
+		s.deformed[i].Points = appendDeformed(s.deformed[i].Points[:0], c.Points, c.Closed, deformStep/scale, s.Deformers)
+		s.deformed[i].Closed = c.Closed
+	}
+	s.contours = s.deformed
+	return s.contours
+}
+
+// samePoints reports whether a and b are the same slice (not just equal).
+func samePoints(a, b []Vec2D) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// flatContours returns the shape's outline in local space, with curves
+// flattened to within tol.
+func (s *Shape) flatContours(tol float32) []Contour {
+	if s.Path == nil {
+		s.pointsContour[0] = Contour{Points: s.Points, Closed: true}
+		return s.pointsContour[:]
+	}
+
+	s.flat = s.Path.AppendFlattened(s.flat, tol)
+	s.flatPath = s.Path
+	s.flatVersion = s.Path.version
+	return s.flat
+}
+
+// WorldContours returns the shape's outline in world space, after deformers
+// and its world matrix have been applied. The result is only valid until the
+// next call.
+func (s *Shape) WorldContours() []Contour {
+	m := s.World()
+	contours := s.localContours(m)
+
+	for len(s.world) < len(contours) {
+		s.world = append(s.world, Contour{})
+	}
+	s.world = s.world[:len(contours)]
+	for i, c := range contours {
+		pts := s.world[i].Points[:0]
+		for _, p := range c.Points {
+			pts = append(pts, m.MultiplyVec2D(p))
+		}
+		s.world[i] = Contour{Points: pts, Closed: c.Closed}
+	}
+	return s.world
+}
+
+// AppendTriangles appends the triangles that fill and stroke the shape (in
+// world space, with premultiplied colors set) to vs and is. There may be too
+// many vertices for 16-bit indices, which is why Renderer splits them up.
+func (s *Shape) AppendTriangles(vs []ebiten.Vertex, is []uint32) ([]ebiten.Vertex, []uint32) {
+	m := s.World()
+	contours := s.localContours(m)
+	if len(contours) == 0 {
+		return vs, is
+	}
+
+	if s.Fill != nil {
+		s.updateFill(contours, m.MaxScale())
+		if s.fillGradient {
+			vs, is = appendTransformed(vs, is, s.fillVs, s.fillIs, m)
+		} else {
+			vStart := len(vs)
+			vs, is = appendTransformed(vs, is, s.fillVs, s.fillIs, m)
+			r, g, b, a := s.Fill.RGBAAt(Vec2D{})
+			setVertexColors(vs[vStart:], r, g, b, a)
+		}
+	}
+
+	if s.Color != nil && s.StrokeWidth > 0 {
+		vStart := len(vs)
+		if scale, ok := m.UniformScale(); ok {
+			s.updateStroke(contours, scale)
+			vs, is = appendTransformed(vs, is, s.strokeVs, s.strokeIs, m)
+		} else {
+			// stretched or sheared strokes would be the wrong width, so are built in world space
+			for _, c := range s.WorldContours() {
+				vs, is = appendStrokeVerticesAndIndices(vs, is, c.Points, c.Closed, s.StrokeWidth, s.Stroke)
+			}
+		}
+		r, g, b, a := colorToFloats(s.Color)
+		setVertexColors(vs[vStart:], r, g, b, a)
+	}
+
+	return vs, is
+}
+
+// updateFill rebuilds the cached fill triangles, if they're out of date.
+func (s *Shape) updateFill(contours []Contour, scale float32) {
+	gradient := isGradient(s.Fill)
+	if s.fillValid && s.fillRule == s.FillRule && s.fillGradient == gradient {
+		return
+	}
+	s.fillValid = true
+	s.fillRule = s.FillRule
+	s.fillGradient = gradient
+
+	s.fillContours = s.fillContours[:0]
+	for _, c := range contours {
+		s.fillContours = append(s.fillContours, c.Points)
+	}
+	s.fillVs, s.fillIs = appendFillVerticesAndIndices(s.fillVs[:0], s.fillIs[:0], s.fillContours, s.FillRule)
+	if gradient {
+		s.fillVs, s.fillIs = subdivideTriangles(s.fillVs, s.fillIs, 0, gradientStep/scale)
+		for i := range s.fillVs {
+			v := &s.fillVs[i]
+			v.ColorR, v.ColorG, v.ColorB, v.ColorA = s.Fill.RGBAAt(Vec2D{v.DstX, v.DstY})
+		}
+	}
+}
+
+// updateStroke rebuilds the cached stroke triangles, if they're out of date
+// or were built for a different scale.
+func (s *Shape) updateStroke(contours []Contour, scale float32) {
+	if s.strokeScale == scale && s.strokeWidth == s.StrokeWidth {
+		return
+	}
+	s.strokeScale = scale
+	s.strokeWidth = s.StrokeWidth
+
+	// the stroke is built in local space, so pixel sizes are scaled down to match
+	style := s.Stroke
+	s.strokeDashes = s.strokeDashes[:0]
+	for _, d := range style.Dashes {
+		s.strokeDashes = append(s.strokeDashes, d/scale)
+	}
+	style.Dashes = s.strokeDashes
+	style.DashOffset /= scale
+
+	s.strokeVs, s.strokeIs = s.strokeVs[:0], s.strokeIs[:0]
+	for _, c := range contours {
+		s.strokeVs, s.strokeIs = appendStrokeVerticesAndIndices(s.strokeVs, s.strokeIs, c.Points, c.Closed, s.StrokeWidth/scale, style)
+	}
+}
+
+// appendTransformed appends the triangles in src (whose indices are relative
+// to srcVs) to vs and is, transforming their positions by m.
+func appendTransformed(vs []ebiten.Vertex, is []uint32, srcVs []ebiten.Vertex, srcIs []uint32, m Matrix3x3) ([]ebiten.Vertex, []uint32) {
+	base := uint32(len(vs))
+	for _, v := range srcVs {
+		p := m.MultiplyVec2D(Vec2D{v.DstX, v.DstY})
+		v.DstX, v.DstY = p.X, p.Y
+		vs = append(vs, v)
+	}
+	for _, i := range srcIs {
+		is = append(is, base+i)
+	}
+	return vs, is
+}
+
+// setVertexColors sets every vertex in vs to the same (premultiplied) color.
+func setVertexColors(vs []ebiten.Vertex, r, g, b, a float32) {
+	for i := range vs {
+		vs[i].ColorR, vs[i].ColorG, vs[i].ColorB, vs[i].ColorA = r, g, b, a
+	}
 }
 
+// Draw fills and strokes the shape onto screen right away. To draw many
+// shapes, queue them on a Renderer instead, which needs fewer draw calls.
 func (s *Shape) Draw(screen *ebiten.Image) {
-	drawShape(screen, s.Points, s.StrokeWidth, s.Color)
+	if s.renderer == nil {
+		s.renderer = NewRenderer()
+	}
+	s.renderer.DrawShape(s)
+	s.renderer.Flush(screen)
 }
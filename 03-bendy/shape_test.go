@@ -0,0 +1,135 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// vertexBounds returns the smallest rect containing every vertex in vs.
+func vertexBounds(vs []ebiten.Vertex) Rect {
+	r := EmptyRect()
+	for _, v := range vs {
+		r = r.Extend(Vec2D{v.DstX, v.DstY})
+	}
+	return r
+}
+
+func TestShape_TransformsCachedTriangles(t *testing.T) {
+	s := NewShape([]Vec2D{{0, 0}, {30, 0}, {10, 10}, {0, 20}}, color.White, 2)
+	s.Fill = SolidPaint{Color: color.Gray{Y: 0x80}}
+	s.Xfm.SetPos(Vec2D{100, 100})
+	before, is := s.AppendTriangles(nil, nil)
+
+	// moving and turning the shape only transforms the triangles it already has
+	s.Xfm.SetPos(Vec2D{200, 50})
+	s.Xfm.SetRot(0.5)
+	after, afterIs := s.AppendTriangles(nil, nil)
+	if len(after) != len(before) || len(afterIs) != len(is) {
+		t.Fatalf("expected the same triangles, got %d vertices instead of %d", len(after), len(before))
+	}
+	m := s.World().Multiply(Translation3x3(-100, -100))
+	for i := range before {
+		want := m.MultiplyVec2D(Vec2D{before[i].DstX, before[i].DstY})
+		if got := (Vec2D{after[i].DstX, after[i].DstY}); got.Distance(want) > 1e-3 {
+			t.Errorf("vertex %d: expected %v, got %v", i, want, got)
+		}
+	}
+
+	// and doesn't allocate, once the buffers are big enough
+	vs := after[:0]
+	if allocs := testing.AllocsPerRun(10, func() {
+		s.Xfm.SetRot(s.Xfm.Rot() + 0.1)
+		vs, is = s.AppendTriangles(vs[:0], is[:0])
+	}); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestShape_KeepsContoursWhenScaled(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Fill    Paint
+		Rebuild bool
+	}{
+		{"no fill", nil, false},
+		{"solid fill", SolidPaint{Color: color.White}, false},
+		{"gradient fill", LinearGradient{Start: Vec2D{0, 0}, End: Vec2D{10, 0}}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s := NewShape(square(0, 0, 10), color.White, 1)
+			s.Fill = tc.Fill
+			s.AppendTriangles(nil, nil)
+			tol := s.flatTolerance
+
+			// polygons without curves or deformers look the same at any scale,
+			// so only a gradient needs rebuilding, to split it into finer triangles
+			s.Xfm.SetUniformScale(10)
+			s.AppendTriangles(nil, nil)
+			if rebuilt := s.flatTolerance != tol; rebuilt != tc.Rebuild {
+				t.Errorf("expected rebuilt to be %v, got %v", tc.Rebuild, rebuilt)
+			}
+		})
+	}
+}
+
+func TestShape_StrokeWidthUnaffectedByScale(t *testing.T) {
+	var p Path
+	p.MoveTo(Vec2D{0, 0})
+	p.LineTo(Vec2D{10, 0})
+	s := NewPathShape(&p, color.White, 2)
+
+	cases := []struct {
+		Name     string
+		Scale    Vec2D
+		Expected Vec2D // size of the stroke's bounds
+	}{
+		{"unscaled", Vec2D{1, 1}, Vec2D{10, 2}},
+		{"uniform", Vec2D{3, 3}, Vec2D{30, 2}},
+		{"back again", Vec2D{1, 1}, Vec2D{10, 2}},
+		{"stretched", Vec2D{3, 5}, Vec2D{30, 2}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s.Xfm.SetScale(tc.Scale)
+			vs, _ := s.AppendTriangles(nil, nil)
+			assertVec(t, vertexBounds(vs).Size(), tc.Expected)
+		})
+	}
+}
+
+func TestShape_NoticesChanges(t *testing.T) {
+	s := NewShape(square(0, 0, 10), color.White, 2)
+	s.Fill = SolidPaint{Color: color.White}
+	s.AppendTriangles(nil, nil)
+
+	check := func(name string, size float32, fill float32) {
+		t.Helper()
+		vs, _ := s.AppendTriangles(nil, nil)
+		if got := vertexBounds(vs).Size().X; got < size-0.01 || got > size+0.01 {
+			t.Errorf("%s: expected width %v, got %v", name, size, got)
+		}
+		if vs[0].ColorR != fill {
+			t.Errorf("%s: expected fill color %v, got %v", name, fill, vs[0].ColorR)
+		}
+	}
+	check("initial", 12, 1)
+
+	s.Points = square(0, 0, 20)
+	check("new points", 22, 1)
+
+	s.StrokeWidth = 4
+	check("stroke width", 24, 1)
+
+	s.Fill = SolidPaint{Color: color.Black}
+	check("fill color", 24, 0)
+
+	// editing points in place isn't noticed without Invalidate
+	s.Points[1].X, s.Points[2].X = 30, 30
+	check("edited in place", 24, 0)
+	s.Invalidate()
+	check("invalidated", 34, 0)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// Sound is one of the game's sound effects.
+type Sound int
+
+const (
+	SoundTick  Sound = iota // the dial turned a step
+	SoundClick              // the dial reached the goal
+	soundCount
+)
+
+var soundNames = [soundCount]string{"tick", "click"}
+
+func (s Sound) String() string {
+	if s < 0 || s >= soundCount {
+		return fmt.Sprintf("Sound(%d)", int(s))
+	}
+	return soundNames[s]
+}
+
+// parseSound returns the sound with the given name (see Sound.String).
+func parseSound(name string) (Sound, error) {
+	for s, n := range soundNames {
+		if n == name {
+			return Sound(s), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown sound \"%s\" (expected one of: %s)", name, strings.Join(soundNames[:], ", "))
+}
+
+// SoundPlayer plays the game's sound effects.
+type SoundPlayer interface {
+	Play(s Sound)
+}
+
+// NullSounds drops every sound, for when there's nothing to play them on,
+// like when taking screenshots.
+type NullSounds struct{}
+
+func (NullSounds) Play(Sound) {}
+
+// MultiSounds plays each sound on all of its players, e.g. to play sounds
+// both locally and on an external synth.
+type MultiSounds []SoundPlayer
+
+func (ms MultiSounds) Play(s Sound) {
+	for _, p := range ms {
+		p.Play(s)
+	}
+}
+
+// sampleRate is the rate (in samples per second) sounds are synthesized at.
+const sampleRate = 44100
+
+// synthesize returns the samples for s, in the range [-1,1].
+func synthesize(s Sound) []float32 {
+	switch s {
+	case SoundTick:
+		return synthTick()
+	case SoundClick:
+		return synthClick()
+	}
+	return nil
+}
+
+// synthTick is a short, bright tick, like the detent of a dial: a burst of
+// noise with a high ping, both dying away within a few milliseconds.
+func synthTick() []float32 {
+	const (
+		length = 0.03 // seconds
+		decay  = 250  // per second
+		ping   = 3200 // Hz
+	)
+	rng := rand.New(rand.NewSource(1))
+	samples := make([]float32, int(length*sampleRate))
+	for i := range samples {
+		t := float64(i) / sampleRate
+		env := math.Exp(-decay * t)
+		noise := rng.Float64()*2 - 1
+		samples[i] = float32(env * (0.5*noise + 0.5*math.Sin(2*math.Pi*ping*t)))
+	}
+	return fadeOut(samples, 0.005)
+}
+
+// synthClick is a heavier, lower click, like a lock's tumbler dropping into
+// place: a sharp knock followed by a short, falling tone.
+func synthClick() []float32 {
+	const (
+		length     = 0.25 // seconds
+		knockDecay = 120  // per second
+		toneDecay  = 18   // per second
+	)
+	rng := rand.New(rand.NewSource(2))
+	samples := make([]float32, int(length*sampleRate))
+	var phase float64
+	for i := range samples {
+		t := float64(i) / sampleRate
+		knock := math.Exp(-knockDecay*t) * (rng.Float64()*2 - 1)
+
+		// the tone drops an octave over the first 50ms
+		freq := 440 * (1 + math.Exp(-t/0.05))
+		phase += 2 * math.Pi * freq / sampleRate
+		tone := math.Exp(-toneDecay*t) * (math.Sin(phase) + 0.3*math.Sin(3*phase))
+
+		samples[i] = float32(0.6*knock + 0.5*tone)
+	}
+	return fadeOut(samples, 0.02)
+}
+
+// fadeOut ramps the last seconds of samples down to silence, so the sound
+// doesn't end with a pop.
+func fadeOut(samples []float32, seconds float64) []float32 {
+	n := int(seconds * sampleRate)
+	if n > len(samples) {
+		n = len(samples)
+	}
+	for i := 0; i < n; i++ {
+		samples[len(samples)-1-i] *= float32(i) / float32(n)
+	}
+	return samples
+}
+
+// toPCM16Stereo converts samples to signed 16-bit little endian stereo, the
+// format ebiten's audio package plays. Samples outside [-1,1] are clipped.
+func toPCM16Stereo(samples []float32) []byte {
+	b := make([]byte, 4*len(samples))
+	for i, s := range samples {
+		v := int16(math.Round(float64(min32(max32(s, -1), 1)) * math.MaxInt16))
+		binary.LittleEndian.PutUint16(b[4*i:], uint16(v))
+		binary.LittleEndian.PutUint16(b[4*i+2:], uint16(v))
+	}
+	return b
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSynthesize(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Sound     Sound
+		MaxLength float64 // seconds
+	}{
+		{"tick", SoundTick, 0.05},
+		{"click", SoundClick, 0.5},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			samples := synthesize(tc.Sound)
+			if len(samples) == 0 || float64(len(samples)) > tc.MaxLength*sampleRate {
+				t.Fatalf("expected up to %vs of samples, got %d", tc.MaxLength, len(samples))
+			}
+
+			var peak float32
+			for _, s := range samples {
+				peak = max32(peak, float32(math.Abs(float64(s))))
+			}
+			if peak < 0.2 || peak > 1 {
+				t.Errorf("expected a peak that's audible without clipping, got %v", peak)
+			}
+			// faded out, so it ends without a pop
+			if last := samples[len(samples)-1]; last != 0 {
+				t.Errorf("expected the last sample to be silent, got %v", last)
+			}
+
+			// the same every time
+			again := synthesize(tc.Sound)
+			for i := range samples {
+				if samples[i] != again[i] {
+					t.Fatalf("sample %d differs between runs: %v != %v", i, samples[i], again[i])
+				}
+			}
+		})
+	}
+
+	if len(synthesize(SoundClick)) <= len(synthesize(SoundTick)) {
+		t.Errorf("expected the click to ring on longer than a tick")
+	}
+}
+
+func TestToPCM16Stereo(t *testing.T) {
+	b := toPCM16Stereo([]float32{0, 1, -1, 2, 0.5})
+	expected := []int16{0, math.MaxInt16, -math.MaxInt16, math.MaxInt16, 16384}
+	if len(b) != 4*len(expected) {
+		t.Fatalf("expected %d bytes, got %d", 4*len(expected), len(b))
+	}
+	for i, e := range expected {
+		left := int16(uint16(b[4*i]) | uint16(b[4*i+1])<<8)
+		right := int16(uint16(b[4*i+2]) | uint16(b[4*i+3])<<8)
+		if left != e || right != e {
+			t.Errorf("sample %d: expected %d in both channels, got %d and %d", i, e, left, right)
+		}
+	}
+}
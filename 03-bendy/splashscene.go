@@ -5,10 +5,10 @@ import (
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
 
 type SplashScene struct {
+	game         *GameScene // switched to once loading is done
 	start        time.Time
 	chFromScript chan string
 	chToScript   chan string
@@ -16,8 +16,10 @@ type SplashScene struct {
 	runScript    bool
 }
 
-func NewSplashScene() *SplashScene {
+// NewSplashScene returns a scene that shows loading messages, then switches to game.
+func NewSplashScene(game *GameScene) *SplashScene {
 	return &SplashScene{
+		game:         game,
 		start:        time.Time{},
 		chFromScript: make(chan string),
 		chToScript:   make(chan string),
@@ -35,7 +37,7 @@ func (s *SplashScene) AddMsg(msg string) {
 
 func (s *SplashScene) Update(mgr *SceneMgr) error {
 	if !s.runScript {
-		mgr.AddScene(SceneGame, NewGameScene(nil))
+		mgr.AddScene(SceneGame, s.game)
 		s.runScript = true
 		go s.Script(s.chFromScript)
 	}
@@ -61,7 +63,7 @@ loop:
 }
 
 func (s *SplashScene) Draw(mgr *SceneMgr, screen *ebiten.Image) {
-	ebitenutil.DebugPrint(screen, strings.Join(s.msgs, "\n"))
+	DrawText(screen, strings.Join(s.msgs, "\n"), Vec2D{X: margin, Y: margin}, TextStyle{})
 }
 
 func (s *SplashScene) Script(ch chan string) {
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// StrokeStyle controls how a shape's outline is drawn. The zero value draws a
+// solid line with mitered corners that are all beveled (as MiterLimit is 0).
+type StrokeStyle struct {
+	LineJoin   vector.LineJoin
+	LineCap    vector.LineCap // only used by dashes and open subpaths
+	MiterLimit float32        // see https://developer.mozilla.org/en-US/docs/Web/SVG/Attribute/stroke-miterlimit
+
+	// Dashes alternates between the lengths (in pixels) of dashes and gaps.
+	// As in SVG, an odd number of lengths is repeated to make it even.
+	// If empty, the outline is solid.
+	Dashes     []float32
+	DashOffset float32 // how far into the dash pattern to start
+}
+
+// strokeRunPoints is the most points stroked at once. vector.Path only makes
+// 16-bit indices, so long outlines are stroked in runs of up to this many
+// points, which keeps each run well under 65536 vertices.
+const strokeRunPoints = 2048
+
+// appendStrokeVerticesAndIndices appends the triangles needed to stroke the
+// outline through points.
+func appendStrokeVerticesAndIndices(vs []ebiten.Vertex, is []uint32, points []Vec2D, closed bool, width float32, style StrokeStyle) ([]ebiten.Vertex, []uint32) {
+	if len(points) < 2 || width <= 0 {
+		return vs, is
+	}
+
+	opts := &vector.StrokeOptions{
+		Width:      width,
+		LineJoin:   style.LineJoin,
+		LineCap:    style.LineCap,
+		MiterLimit: style.MiterLimit,
+	}
+
+	if dashes := evenDashes(style.Dashes); len(dashes) > 0 {
+		for _, dash := range dashPolyline(points, closed, dashes, style.DashOffset) {
+			vs, is = appendStrokeRuns(vs, is, dash, opts)
+		}
+		return vs, is
+	}
+
+	if closed && len(points) <= strokeRunPoints {
+		var path vector.Path
+		path.MoveTo(points[0].X, points[0].Y)
+		for _, v := range points[1:] {
+			path.LineTo(v.X, v.Y)
+		}
+		path.Close()
+		return appendStroke(vs, is, &path, opts)
+	}
+	if closed {
+		// go around again as far as the second point, so the join at the first point is
+		// drawn, and use butt caps so the ends don't stick out past it
+		points = append(append(make([]Vec2D, 0, len(points)+2), points...), points[0], points[1])
+		butt := *opts
+		butt.LineCap = vector.LineCapButt
+		opts = &butt
+	}
+	return appendStrokeRuns(vs, is, points, opts)
+}
+
+// appendStrokeRuns strokes the open polyline through points, in runs of at
+// most strokeRunPoints. Each run starts a segment before the last one ended,
+// so the join between them is drawn, at the cost of drawing that segment twice.
+func appendStrokeRuns(vs []ebiten.Vertex, is []uint32, points []Vec2D, opts *vector.StrokeOptions) ([]ebiten.Vertex, []uint32) {
+	for start := 0; start+1 < len(points); {
+		end := start + strokeRunPoints
+		if end > len(points) {
+			end = len(points)
+		}
+		var path vector.Path
+		path.MoveTo(points[start].X, points[start].Y)
+		for _, v := range points[start+1 : end] {
+			path.LineTo(v.X, v.Y)
+		}
+		vs, is = appendStroke(vs, is, &path, opts)
+		if end == len(points) {
+			break
+		}
+		start = end - 2
+	}
+	return vs, is
+}
+
+// appendStroke appends the triangles that stroke path. vector.Path's indices
+// wrap around past 65535, but as long as path needs fewer vertices than that,
+// their offset from the first new vertex is still right modulo 2^16.
+func appendStroke(vs []ebiten.Vertex, is []uint32, path *vector.Path, opts *vector.StrokeOptions) ([]ebiten.Vertex, []uint32) {
+	base := len(vs)
+	vs, is16 := path.AppendVerticesAndIndicesForStroke(vs, nil, opts)
+	for _, i := range is16 {
+		is = append(is, uint32(base)+uint32(i-uint16(base)))
+	}
+	return vs, is
+}
+
+// evenDashes returns the dash pattern with an even number of entries, or nil
+// if the pattern can't be used (e.g. it has negative or all zero lengths).
+func evenDashes(dashes []float32) []float32 {
+	var total float32
+	for _, d := range dashes {
+		if d < 0 {
+			return nil
+		}
+		total += d
+	}
+	if total <= 0 {
+		return nil
+	}
+	if len(dashes)%2 == 1 {
+		return append(append([]float32(nil), dashes...), dashes...)
+	}
+	return dashes
+}
+
+// dashPolyline walks the outline through points, and returns the pieces of
+// it that fall on the "on" parts of the (even length) dash pattern.
+func dashPolyline(points []Vec2D, closed bool, dashes []float32, offset float32) [][]Vec2D {
+	var total float32
+	for _, d := range dashes {
+		total += d
+	}
+
+	// find where in the pattern to start
+	offset -= float32(int(offset/total)) * total
+	if offset < 0 {
+		offset += total
+	}
+	idx := 0
+	for offset >= dashes[idx] {
+		offset -= dashes[idx]
+		idx = (idx + 1) % len(dashes)
+	}
+	remaining := dashes[idx] - offset
+	on := idx%2 == 0
+
+	var out [][]Vec2D
+	var cur []Vec2D
+	if on {
+		cur = []Vec2D{points[0]}
+	}
+
+	segments := len(points)
+	if !closed {
+		segments--
+	}
+	for i := 0; i < segments; i++ {
+		a, b := points[i], points[(i+1)%len(points)]
+		segLen := a.Distance(b)
+		pos := float32(0)
+		for segLen-pos > remaining {
+			pos += remaining
+			p := a.Lerp(b, pos/segLen)
+			if on {
+				out = append(out, append(cur, p))
+				cur = nil
+			} else {
+				cur = []Vec2D{p}
+			}
+			on = !on
+			idx = (idx + 1) % len(dashes)
+			remaining = dashes[idx]
+		}
+		remaining -= segLen - pos
+		if on {
+			cur = append(cur, b)
+		}
+	}
+	if on && len(cur) > 1 {
+		out = append(out, cur)
+	}
+
+	return out
+}
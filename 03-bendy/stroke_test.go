@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEvenDashes(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Dashes []float32
+		Expect []float32
+	}{
+		{"empty", nil, nil},
+		{"even", []float32{4, 2}, []float32{4, 2}},
+		{"odd", []float32{4, 2, 1}, []float32{4, 2, 1, 4, 2, 1}},
+		{"all zero", []float32{0, 0}, nil},
+		{"negative", []float32{4, -2}, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			got := evenDashes(tc.Dashes)
+			if len(got) != len(tc.Expect) {
+				t.Fatalf("expected %v, got %v", tc.Expect, got)
+			}
+			for i := range got {
+				if got[i] != tc.Expect[i] {
+					t.Fatalf("expected %v, got %v", tc.Expect, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDashPolyline(t *testing.T) {
+	// perimeter of 40, so a 4-on 1-off pattern gives 8 dashes
+	sq := square(0, 0, 10)
+
+	cases := []struct {
+		Name      string
+		Dashes    []float32
+		Offset    float32
+		Count     int
+		OnLength  float32
+		FirstDash Vec2D
+	}{
+		{"simple", []float32{4, 1}, 0, 8, 32, Vec2D{0, 0}},
+		{"offset into dash", []float32{4, 1}, 2, 9, 32, Vec2D{0, 0}},
+		{"offset into gap", []float32{4, 1}, 4.5, 8, 32, Vec2D{0.5, 0}},
+		{"negative offset", []float32{4, 1}, -1, 8, 32, Vec2D{1, 0}},
+		{"wraps corners", []float32{15, 5}, 0, 2, 30, Vec2D{0, 0}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			dashes := dashPolyline(sq, true, tc.Dashes, tc.Offset)
+			if len(dashes) != tc.Count {
+				t.Fatalf("expected %d dashes, got %d: %v", tc.Count, len(dashes), dashes)
+			}
+			var total float32
+			for _, d := range dashes {
+				for i := 1; i < len(d); i++ {
+					total += d[i-1].Distance(d[i])
+				}
+			}
+			assertNear(t, "total length", total, tc.OnLength)
+			assertVec(t, dashes[0][0], tc.FirstDash)
+		})
+	}
+
+	// open outlines don't include the edge from the last point back to the first
+	dashes := dashPolyline(sq, false, []float32{4, 1}, 0)
+	if len(dashes) != 6 {
+		t.Fatalf("expected 6 dashes, got %d: %v", len(dashes), dashes)
+	}
+	assertVec(t, dashes[5][len(dashes[5])-1], Vec2D{1, 10})
+}
+
+func TestAppendStrokeVerticesAndIndices_Long(t *testing.T) {
+	// too many points for one vector.Path, so it's stroked in runs
+	points := make([]Vec2D, 20000)
+	for i := range points {
+		points[i] = Vec2D{float32(i), 0}
+	}
+	vs, is := appendStrokeVerticesAndIndices(nil, nil, points, false, 2, StrokeStyle{})
+	if len(vs) <= math.MaxUint16 {
+		t.Fatalf("expected more than %d vertices, got %d", math.MaxUint16, len(vs))
+	}
+	for _, i := range is {
+		if int(i) >= len(vs) {
+			t.Fatalf("index %d is out of range", i)
+		}
+	}
+	// runs overlap by a segment, so a little extra is drawn
+	if area := meshArea(vs, is); area < 2*19999 || area > 2*19999*1.01 {
+		t.Errorf("expected area of about %v, got %v", 2*19999, area)
+	}
+}
@@ -0,0 +1,985 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// SVGImage is the set of shapes loaded from an SVG file, in the order they are drawn.
+type SVGImage struct {
+	Width, Height float32 // size of the root viewBox (or width and height, if there is no viewBox)
+	Shapes        []*Shape
+}
+
+// LoadSVG reads shapes from an SVG file, such as one saved by Inkscape. Only
+// a subset of SVG is understood: path, polygon, polyline, line, rect, circle
+// and ellipse elements, optionally in groups, with transforms, solid fill and
+// stroke colors, and the stroke styles that Shape supports. Anything else
+// that would change how the image looks is an error, rather than being
+// silently drawn wrong.
+//
+// Each shape's transform is set from the transforms of the element and its
+// groups, and coordinates are shifted so the top-left of the viewBox is at
+// the origin. Group opacity is applied to each shape in the group, which only
+// matches SVG where shapes in a group don't overlap.
+func LoadSVG(path string) (SVGImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return SVGImage{}, err
+	}
+	defer f.Close()
+
+	img, err := ParseSVG(f)
+	if err != nil {
+		return SVGImage{}, fmt.Errorf("LoadSVG(%s): %w", path, err)
+	}
+	return img, nil
+}
+
+// ParseSVG reads shapes from an SVG document (see LoadSVG).
+func ParseSVG(r io.Reader) (SVGImage, error) {
+	var img SVGImage
+	d := xml.NewDecoder(r)
+
+	stack := []svgState{{style: defaultSVGStyle(), xfm: Identity3x3()}}
+	foundRoot := false
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return SVGImage{}, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			line, _ := d.InputPos()
+			wrap := func(err error) error {
+				return fmt.Errorf("line %d: <%s>: %w", line, t.Name.Local, err)
+			}
+
+			if t.Name.Space != "" && t.Name.Space != svgNamespace {
+				// editor metadata, e.g. sodipodi:namedview
+				if err := d.Skip(); err != nil {
+					return SVGImage{}, err
+				}
+				continue
+			}
+
+			top := stack[len(stack)-1]
+			if t.Name.Local == "svg" && foundRoot {
+				return SVGImage{}, wrap(errors.New("nested <svg> elements are not supported"))
+			}
+			if !foundRoot {
+				if t.Name.Local != "svg" {
+					return SVGImage{}, wrap(errors.New("root element must be <svg>"))
+				}
+				foundRoot = true
+				attrs := svgAttrs(t.Attr)
+				w, h, origin, err := parseSVGViewport(attrs)
+				if err != nil {
+					return SVGImage{}, wrap(err)
+				}
+				img.Width, img.Height = w, h
+				top.xfm = Translation3x3(-origin.X, -origin.Y)
+			}
+
+			switch t.Name.Local {
+			case "svg", "g":
+				next, err := top.apply(svgAttrs(t.Attr))
+				if err != nil {
+					return SVGImage{}, wrap(err)
+				}
+				stack = append(stack, next)
+				continue
+
+			case "defs", "metadata", "title", "desc":
+				// defs only holds things that are referenced elsewhere, and
+				// references are rejected where they're used
+				if err := d.Skip(); err != nil {
+					return SVGImage{}, err
+				}
+				continue
+
+			case "path", "polygon", "polyline", "line", "rect", "circle", "ellipse":
+				attrs := svgAttrs(t.Attr)
+				next, err := top.apply(attrs)
+				if err != nil {
+					return SVGImage{}, wrap(err)
+				}
+				path, err := svgElementPath(t.Name.Local, attrs)
+				if err != nil {
+					return SVGImage{}, wrap(err)
+				}
+				if !next.hidden && !next.style.invisible {
+					img.Shapes = append(img.Shapes, next.style.newShape(path, next.xfm))
+				}
+				if err := d.Skip(); err != nil {
+					return SVGImage{}, err
+				}
+				continue
+
+			default:
+				return SVGImage{}, wrap(errors.New("unsupported element"))
+			}
+
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if !foundRoot {
+		return SVGImage{}, errors.New("no <svg> element")
+	}
+	return img, nil
+}
+
+const svgNamespace = "http://www.w3.org/2000/svg"
+
+// svgAttrs returns the attributes that are in the SVG namespace (which
+// includes those without a namespace), with properties from any style
+// attribute overriding the attributes of the same name.
+func svgAttrs(attrs []xml.Attr) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		if a.Name.Space == "" || a.Name.Space == svgNamespace {
+			m[a.Name.Local] = strings.TrimSpace(a.Value)
+		}
+	}
+	if style, ok := m["style"]; ok {
+		for _, decl := range strings.Split(style, ";") {
+			name, value, ok := strings.Cut(decl, ":")
+			if ok {
+				m[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return m
+}
+
+// parseSVGViewport returns the size of the root svg element, and the point that should be moved to the origin.
+func parseSVGViewport(attrs map[string]string) (w, h float32, origin Vec2D, err error) {
+	if vb, ok := attrs["viewBox"]; ok {
+		nums, err := parseSVGNumbers(vb)
+		if err != nil {
+			return 0, 0, Vec2D{}, fmt.Errorf("viewBox: %w", err)
+		}
+		if len(nums) != 4 {
+			return 0, 0, Vec2D{}, fmt.Errorf("viewBox: expected 4 numbers, got %d", len(nums))
+		}
+		return nums[2], nums[3], Vec2D{nums[0], nums[1]}, nil
+	}
+	for _, name := range []string{"width", "height"} {
+		v, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		n, err := parseSVGLength(v)
+		if err != nil {
+			return 0, 0, Vec2D{}, fmt.Errorf("%s: %w", name, err)
+		}
+		if name == "width" {
+			w = n
+		} else {
+			h = n
+		}
+	}
+	return w, h, Vec2D{}, nil
+}
+
+// svgState is what an element inherits from its groups.
+type svgState struct {
+	style  svgStyle
+	xfm    Matrix3x3
+	hidden bool // display:none, which children can't undo
+}
+
+// svgStyle is the presentation state that elements inherit from their groups.
+type svgStyle struct {
+	color         color.Color // used by fill and stroke set to currentColor
+	fill          color.Color // nil for none
+	fillOpacity   float32
+	fillRule      FillRule
+	stroke        color.Color // nil for none
+	strokeOpacity float32
+	strokeWidth   float32
+	opacity       float32
+	strokeStyle   StrokeStyle
+	invisible     bool // visibility:hidden, which children can undo
+}
+
+func defaultSVGStyle() svgStyle {
+	return svgStyle{
+		color:         color.Black,
+		fill:          color.Black,
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		strokeWidth:   1,
+		opacity:       1,
+		strokeStyle:   StrokeStyle{MiterLimit: 4},
+	}
+}
+
+// newShape returns a shape that draws path in this style, placed by xfm.
+func (s svgStyle) newShape(path *Path, xfm Matrix3x3) *Shape {
+	var stroke color.Color
+	var width float32
+	if s.stroke != nil && s.strokeWidth > 0 {
+		stroke = svgOpacity(s.stroke, s.strokeOpacity*s.opacity)
+		// stroke widths are in pixels, so they don't pick up the shape's scale
+		width = s.strokeWidth * float32(math.Sqrt(math.Abs(float64(xfm.Determinant()))))
+	}
+	style := s.strokeStyle
+	if len(style.Dashes) > 0 && s.strokeWidth > 0 {
+		// dashes are also in pixels
+		k := width / s.strokeWidth
+		style.Dashes = make([]float32, len(s.strokeStyle.Dashes))
+		for i, d := range s.strokeStyle.Dashes {
+			style.Dashes[i] = d * k
+		}
+		style.DashOffset *= k
+	}
+
+	shape := NewPathShape(path, stroke, width)
+	if s.fill != nil {
+		shape.Fill = SolidPaint{Color: svgOpacity(s.fill, s.fillOpacity*s.opacity)}
+	}
+	shape.FillRule = s.fillRule
+	shape.Stroke = style
+	shape.Xfm.SetMatrix(xfm)
+	return shape
+}
+
+// svgOpacity returns c with its alpha multiplied by opacity.
+func svgOpacity(c color.Color, opacity float32) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = uint8(math.Round(float64(n.A) * float64(opacity)))
+	return n
+}
+
+// svgUnsupported lists attributes that change how an element looks in ways we can't draw.
+var svgUnsupported = []string{"clip-path", "mask", "filter", "marker-start", "marker-mid", "marker-end"}
+
+// apply returns the state of an element with attrs, whose parent has state st.
+func (st svgState) apply(attrs map[string]string) (svgState, error) {
+	for _, name := range svgUnsupported {
+		if v, ok := attrs[name]; ok && v != "none" {
+			return st, fmt.Errorf("%s is not supported", name)
+		}
+	}
+	if attrs["display"] == "none" {
+		st.hidden = true
+	}
+	if v, ok := attrs["transform"]; ok {
+		m, err := parseSVGTransform(v)
+		if err != nil {
+			return st, fmt.Errorf("transform: %w", err)
+		}
+		st.xfm = st.xfm.Multiply(m)
+	}
+	style, err := st.style.apply(attrs)
+	if err != nil {
+		return st, err
+	}
+	st.style = style
+	return st, nil
+}
+
+// svgProperties are the style properties we understand, in the order they
+// are applied (color must come before anything that uses currentColor).
+var svgProperties = []string{
+	"color", "fill", "fill-opacity", "fill-rule", "stroke", "stroke-opacity", "stroke-width",
+	"stroke-linejoin", "stroke-linecap", "stroke-miterlimit", "stroke-dasharray", "stroke-dashoffset",
+	"opacity", "visibility",
+}
+
+func (s svgStyle) apply(attrs map[string]string) (svgStyle, error) {
+	for _, name := range svgProperties {
+		v, ok := attrs[name]
+		if !ok || v == "inherit" {
+			continue
+		}
+		if err := s.set(name, v); err != nil {
+			return s, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *svgStyle) set(name, v string) error {
+	var err error
+	switch name {
+	case "color":
+		s.color, err = parseSVGColor(v)
+	case "fill":
+		s.fill, err = s.parsePaint(v)
+	case "stroke":
+		s.stroke, err = s.parsePaint(v)
+	case "fill-opacity":
+		s.fillOpacity, err = parseSVGOpacity(v)
+	case "stroke-opacity":
+		s.strokeOpacity, err = parseSVGOpacity(v)
+	case "opacity":
+		// not really inherited, but multiplying is close enough (see LoadSVG)
+		var o float32
+		o, err = parseSVGOpacity(v)
+		s.opacity *= o
+	case "fill-rule":
+		switch v {
+		case "nonzero":
+			s.fillRule = FillRuleNonZero
+		case "evenodd":
+			s.fillRule = FillRuleEvenOdd
+		default:
+			err = fmt.Errorf("unknown fill rule %q", v)
+		}
+	case "stroke-width":
+		s.strokeWidth, err = parseSVGLength(v)
+	case "stroke-linejoin":
+		switch v {
+		case "miter":
+			s.strokeStyle.LineJoin = vector.LineJoinMiter
+		case "round":
+			s.strokeStyle.LineJoin = vector.LineJoinRound
+		case "bevel":
+			s.strokeStyle.LineJoin = vector.LineJoinBevel
+		default:
+			err = fmt.Errorf("%q is not supported", v)
+		}
+	case "stroke-linecap":
+		switch v {
+		case "butt":
+			s.strokeStyle.LineCap = vector.LineCapButt
+		case "round":
+			s.strokeStyle.LineCap = vector.LineCapRound
+		case "square":
+			s.strokeStyle.LineCap = vector.LineCapSquare
+		default:
+			err = fmt.Errorf("%q is not supported", v)
+		}
+	case "stroke-miterlimit":
+		s.strokeStyle.MiterLimit, err = parseSVGNumber(v)
+	case "stroke-dasharray":
+		s.strokeStyle.Dashes = nil
+		if v != "none" {
+			sc := svgScanner{s: v}
+			for !sc.done() && err == nil {
+				var d float32
+				d, err = sc.length()
+				s.strokeStyle.Dashes = append(s.strokeStyle.Dashes, d)
+			}
+		}
+	case "stroke-dashoffset":
+		s.strokeStyle.DashOffset, err = parseSVGLength(v)
+	case "visibility":
+		s.invisible = v != "visible"
+	}
+	return err
+}
+
+// parsePaint parses the value of a fill or stroke property, returning nil for none.
+func (s svgStyle) parsePaint(v string) (color.Color, error) {
+	switch {
+	case v == "none":
+		return nil, nil
+	case v == "currentColor":
+		return s.color, nil
+	case strings.HasPrefix(v, "url("):
+		return nil, fmt.Errorf("gradients and patterns (%s) are not supported", v)
+	}
+	return parseSVGColor(v)
+}
+
+func parseSVGOpacity(v string) (float32, error) {
+	var o float32
+	var err error
+	if pct, ok := strings.CutSuffix(v, "%"); ok {
+		o, err = parseSVGNumber(pct)
+		o /= 100
+	} else {
+		o, err = parseSVGNumber(v)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return float32(math.Max(0, math.Min(1, float64(o)))), nil
+}
+
+// svgNamedColors are the CSS basic colors, plus a few more that Inkscape is fond of.
+var svgNamedColors = map[string]color.NRGBA{
+	"black":       {0x00, 0x00, 0x00, 0xff},
+	"silver":      {0xc0, 0xc0, 0xc0, 0xff},
+	"gray":        {0x80, 0x80, 0x80, 0xff},
+	"grey":        {0x80, 0x80, 0x80, 0xff},
+	"white":       {0xff, 0xff, 0xff, 0xff},
+	"maroon":      {0x80, 0x00, 0x00, 0xff},
+	"red":         {0xff, 0x00, 0x00, 0xff},
+	"purple":      {0x80, 0x00, 0x80, 0xff},
+	"fuchsia":     {0xff, 0x00, 0xff, 0xff},
+	"magenta":     {0xff, 0x00, 0xff, 0xff},
+	"green":       {0x00, 0x80, 0x00, 0xff},
+	"lime":        {0x00, 0xff, 0x00, 0xff},
+	"olive":       {0x80, 0x80, 0x00, 0xff},
+	"yellow":      {0xff, 0xff, 0x00, 0xff},
+	"navy":        {0x00, 0x00, 0x80, 0xff},
+	"blue":        {0x00, 0x00, 0xff, 0xff},
+	"teal":        {0x00, 0x80, 0x80, 0xff},
+	"aqua":        {0x00, 0xff, 0xff, 0xff},
+	"cyan":        {0x00, 0xff, 0xff, 0xff},
+	"orange":      {0xff, 0xa5, 0x00, 0xff},
+	"transparent": {0x00, 0x00, 0x00, 0x00},
+}
+
+// parseSVGColor parses a color keyword, #rgb, #rrggbb (with optional alpha), or rgb()/rgba().
+func parseSVGColor(v string) (color.Color, error) {
+	if c, ok := svgNamedColors[strings.ToLower(v)]; ok {
+		return c, nil
+	}
+
+	if hex, ok := strings.CutPrefix(v, "#"); ok {
+		if len(hex) == 3 || len(hex) == 4 {
+			// each digit is doubled, e.g. #f80 is #ff8800
+			var b strings.Builder
+			for _, r := range hex {
+				b.WriteRune(r)
+				b.WriteRune(r)
+			}
+			hex = b.String()
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 8 {
+			return nil, fmt.Errorf("invalid color %q", v)
+		}
+		return color.NRGBA{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}, nil
+	}
+
+	if fn, args, ok := strings.Cut(v, "("); ok && (fn == "rgb" || fn == "rgba") && strings.HasSuffix(args, ")") {
+		parts := strings.FieldsFunc(strings.TrimSuffix(args, ")"), func(r rune) bool {
+			return r == ',' || r == ' ' || r == '/'
+		})
+		if len(parts) != 3 && len(parts) != 4 {
+			return nil, fmt.Errorf("invalid color %q", v)
+		}
+		var c [4]uint8
+		c[3] = 0xff
+		for i, part := range parts {
+			var f float32
+			var err error
+			if i == 3 {
+				f, err = parseSVGOpacity(part)
+				f *= 255
+			} else if pct, ok := strings.CutSuffix(part, "%"); ok {
+				f, err = parseSVGNumber(pct)
+				f = f * 255 / 100
+			} else {
+				f, err = parseSVGNumber(part)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid color %q", v)
+			}
+			c[i] = uint8(math.Round(math.Max(0, math.Min(255, float64(f)))))
+		}
+		return color.NRGBA{c[0], c[1], c[2], c[3]}, nil
+	}
+
+	return nil, fmt.Errorf("unknown color %q", v)
+}
+
+// parseSVGTransform parses a list of transform functions into a single matrix.
+func parseSVGTransform(v string) (Matrix3x3, error) {
+	m := Identity3x3()
+	rest := v
+	for {
+		rest = strings.TrimLeft(rest, " ,\t\r\n")
+		if rest == "" {
+			return m, nil
+		}
+		name, after, ok := strings.Cut(rest, "(")
+		if !ok {
+			return m, fmt.Errorf("expected '(' after %q", rest)
+		}
+		args, after, ok := strings.Cut(after, ")")
+		if !ok {
+			return m, fmt.Errorf("missing ')' after %q", name)
+		}
+		rest = after
+		name = strings.TrimSpace(name)
+
+		nums, err := parseSVGNumbers(args)
+		if err != nil {
+			return m, fmt.Errorf("%s: %w", name, err)
+		}
+		want := func(counts ...int) error {
+			for _, c := range counts {
+				if len(nums) == c {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s: wrong number of arguments (%d)", name, len(nums))
+		}
+
+		var t Matrix3x3
+		switch name {
+		case "matrix":
+			if err := want(6); err != nil {
+				return m, err
+			}
+			t = Matrix3x3{
+				nums[0], nums[2], nums[4],
+				nums[1], nums[3], nums[5],
+				0, 0, 1,
+			}
+		case "translate":
+			if err := want(1, 2); err != nil {
+				return m, err
+			}
+			nums = append(nums, 0)
+			t = Translation3x3(nums[0], nums[1])
+		case "scale":
+			if err := want(1, 2); err != nil {
+				return m, err
+			}
+			nums = append(nums, nums[0])
+			t = Scale3x3(nums[0], nums[1])
+		case "rotate":
+			if err := want(1, 3); err != nil {
+				return m, err
+			}
+			t = Rotation3x3(nums[0] * math.Pi / 180)
+			if len(nums) == 3 {
+				t = Translation3x3(nums[1], nums[2]).Multiply(t).Multiply(Translation3x3(-nums[1], -nums[2]))
+			}
+		case "skewX":
+			if err := want(1); err != nil {
+				return m, err
+			}
+			t = Shear3x3(float32(math.Tan(float64(nums[0])*math.Pi/180)), 0)
+		case "skewY":
+			if err := want(1); err != nil {
+				return m, err
+			}
+			t = Shear3x3(0, float32(math.Tan(float64(nums[0])*math.Pi/180)))
+		default:
+			return m, fmt.Errorf("unknown transform %q", name)
+		}
+		m = m.Multiply(t)
+	}
+}
+
+// svgElementPath builds the outline of a basic shape element.
+func svgElementPath(name string, attrs map[string]string) (*Path, error) {
+	var p Path
+
+	switch name {
+	case "path":
+		d, ok := attrs["d"]
+		if !ok {
+			return nil, errors.New("missing d attribute")
+		}
+		if err := parseSVGPathData(&p, d); err != nil {
+			return nil, fmt.Errorf("d: %w", err)
+		}
+
+	case "polygon", "polyline":
+		nums, err := parseSVGNumbers(attrs["points"])
+		if err != nil {
+			return nil, fmt.Errorf("points: %w", err)
+		}
+		if len(nums)%2 != 0 {
+			return nil, errors.New("points: odd number of coordinates")
+		}
+		pts := make([]Vec2D, len(nums)/2)
+		for i := range pts {
+			pts[i] = Vec2D{nums[i*2], nums[i*2+1]}
+		}
+		if name == "polygon" {
+			p.Polygon(pts)
+		} else if len(pts) > 0 {
+			p.MoveTo(pts[0])
+			for _, pt := range pts[1:] {
+				p.LineTo(pt)
+			}
+		}
+
+	case "line":
+		v, err := svgLengthAttrs(attrs, "x1", "y1", "x2", "y2")
+		if err != nil {
+			return nil, err
+		}
+		p.MoveTo(Vec2D{v[0], v[1]})
+		p.LineTo(Vec2D{v[2], v[3]})
+
+	case "rect":
+		v, err := svgLengthAttrs(attrs, "x", "y", "width", "height", "rx", "ry")
+		if err != nil {
+			return nil, err
+		}
+		x, y, w, h, rx, ry := v[0], v[1], v[2], v[3], v[4], v[5]
+		if w <= 0 || h <= 0 {
+			break
+		}
+		// a missing radius matches the other one
+		if _, ok := attrs["rx"]; !ok {
+			rx = ry
+		}
+		if _, ok := attrs["ry"]; !ok {
+			ry = rx
+		}
+		rx = float32(math.Min(float64(rx), float64(w/2)))
+		ry = float32(math.Min(float64(ry), float64(h/2)))
+		if rx <= 0 || ry <= 0 {
+			p.Polygon([]Vec2D{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}})
+			break
+		}
+		p.MoveTo(Vec2D{x + rx, y})
+		p.LineTo(Vec2D{x + w - rx, y})
+		p.ArcTo(rx, ry, 0, false, true, Vec2D{x + w, y + ry})
+		p.LineTo(Vec2D{x + w, y + h - ry})
+		p.ArcTo(rx, ry, 0, false, true, Vec2D{x + w - rx, y + h})
+		p.LineTo(Vec2D{x + rx, y + h})
+		p.ArcTo(rx, ry, 0, false, true, Vec2D{x, y + h - ry})
+		p.LineTo(Vec2D{x, y + ry})
+		p.ArcTo(rx, ry, 0, false, true, Vec2D{x + rx, y})
+		p.Close()
+
+	case "circle":
+		v, err := svgLengthAttrs(attrs, "cx", "cy", "r")
+		if err != nil {
+			return nil, err
+		}
+		if v[2] > 0 {
+			p.Circle(Vec2D{v[0], v[1]}, v[2])
+		}
+
+	case "ellipse":
+		v, err := svgLengthAttrs(attrs, "cx", "cy", "rx", "ry")
+		if err != nil {
+			return nil, err
+		}
+		if v[2] > 0 && v[3] > 0 {
+			p.Ellipse(Vec2D{v[0], v[1]}, v[2], v[3])
+		}
+	}
+
+	return &p, nil
+}
+
+// svgLengthAttrs parses the named attributes as lengths, treating missing ones as 0.
+func svgLengthAttrs(attrs map[string]string, names ...string) ([]float32, error) {
+	out := make([]float32, len(names))
+	for i, name := range names {
+		v, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		n, err := parseSVGLength(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+// parseSVGPathData adds the commands in SVG path data d to p.
+func parseSVGPathData(p *Path, d string) error {
+	sc := svgScanner{s: d}
+
+	var err error
+	num := func() float32 {
+		if err != nil {
+			return 0
+		}
+		var n float32
+		n, err = sc.number()
+		return n
+	}
+	flag := func() bool {
+		if err != nil {
+			return false
+		}
+		var f bool
+		f, err = sc.flag()
+		return f
+	}
+	point := func(base Vec2D) Vec2D {
+		x := num()
+		y := num()
+		return Vec2D{x, y}.Add(base)
+	}
+
+	var cmd, prev byte
+	var start, cur, ctrl Vec2D // ctrl is the last control point, for smooth curves
+	for !sc.done() {
+		if c := sc.peek(); (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			cmd = c
+			sc.i++
+			if prev == 0 && cmd != 'M' && cmd != 'm' {
+				return fmt.Errorf("must start with a moveto, not %q", cmd)
+			}
+		} else if cmd == 0 {
+			return errors.New("must start with a command")
+		} else if cmd == 'Z' || cmd == 'z' {
+			return fmt.Errorf("unexpected number after %q", cmd)
+		}
+
+		var base Vec2D
+		rel := cmd >= 'a'
+		if rel {
+			base = cur
+		}
+		upper := cmd &^ 0x20
+
+		switch upper {
+		case 'M':
+			cur = point(base)
+			start = cur
+			p.MoveTo(cur)
+			// extra coordinate pairs are implicit linetos
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L':
+			cur = point(base)
+			p.LineTo(cur)
+		case 'H':
+			x := num()
+			if rel {
+				x += cur.X
+			}
+			cur.X = x
+			p.LineTo(cur)
+		case 'V':
+			y := num()
+			if rel {
+				y += cur.Y
+			}
+			cur.Y = y
+			p.LineTo(cur)
+		case 'C', 'S':
+			c1 := cur
+			if upper == 'C' {
+				c1 = point(base)
+			} else if prev == 'C' || prev == 'S' {
+				c1 = cur.Add(cur.Sub(ctrl))
+			}
+			ctrl = point(base)
+			cur = point(base)
+			p.CubicTo(c1, ctrl, cur)
+		case 'Q', 'T':
+			c := cur
+			if upper == 'Q' {
+				c = point(base)
+			} else if prev == 'Q' || prev == 'T' {
+				c = cur.Add(cur.Sub(ctrl))
+			}
+			ctrl = c
+			cur = point(base)
+			p.QuadTo(c, cur)
+		case 'A':
+			rx := float32(math.Abs(float64(num())))
+			ry := float32(math.Abs(float64(num())))
+			rot := num()
+			largeArc := flag()
+			sweep := flag()
+			cur = point(base)
+			if rx == 0 || ry == 0 {
+				p.LineTo(cur)
+			} else {
+				p.ArcTo(rx, ry, rot*math.Pi/180, largeArc, sweep, cur)
+			}
+		case 'Z':
+			p.Close()
+			cur = start
+		default:
+			return fmt.Errorf("unknown command %q", cmd)
+		}
+		if err != nil {
+			return fmt.Errorf("%c: %w", upper, err)
+		}
+		prev = upper
+	}
+	return nil
+}
+
+// svgScanner reads numbers from SVG attribute values, where separators are
+// optional if the result is unambiguous (e.g. "10-5.5.5" is 10, -5.5, and .5).
+type svgScanner struct {
+	s string
+	i int
+}
+
+func (sc *svgScanner) skipSeparators() {
+	for sc.i < len(sc.s) && strings.IndexByte(" ,\t\r\n", sc.s[sc.i]) >= 0 {
+		sc.i++
+	}
+}
+
+// done skips separators, then reports whether there is anything left to read.
+func (sc *svgScanner) done() bool {
+	sc.skipSeparators()
+	return sc.i >= len(sc.s)
+}
+
+func (sc *svgScanner) peek() byte {
+	return sc.s[sc.i]
+}
+
+// errorf returns an error that includes the text at the scanner's position.
+func (sc *svgScanner) errorf(format string, args ...any) error {
+	rest := sc.s[sc.i:]
+	if rest == "" {
+		return fmt.Errorf(format+" at end of input", args...)
+	}
+	if len(rest) > 10 {
+		rest = rest[:10] + "..."
+	}
+	return fmt.Errorf(format+" at %q", append(args, rest)...)
+}
+
+func (sc *svgScanner) number() (float32, error) {
+	sc.skipSeparators()
+	start := sc.i
+	isDigit := func() bool {
+		return sc.i < len(sc.s) && sc.s[sc.i] >= '0' && sc.s[sc.i] <= '9'
+	}
+
+	if sc.i < len(sc.s) && (sc.s[sc.i] == '-' || sc.s[sc.i] == '+') {
+		sc.i++
+	}
+	digits := 0
+	for ; isDigit(); sc.i++ {
+		digits++
+	}
+	if sc.i < len(sc.s) && sc.s[sc.i] == '.' {
+		sc.i++
+		for ; isDigit(); sc.i++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		sc.i = start
+		return 0, sc.errorf("expected a number")
+	}
+	if sc.i < len(sc.s) && (sc.s[sc.i] == 'e' || sc.s[sc.i] == 'E') {
+		// only an exponent if digits follow, so "1em" isn't mistaken for one
+		j := sc.i + 1
+		if j < len(sc.s) && (sc.s[j] == '-' || sc.s[j] == '+') {
+			j++
+		}
+		if j < len(sc.s) && sc.s[j] >= '0' && sc.s[j] <= '9' {
+			sc.i = j
+			for ; isDigit(); sc.i++ {
+			}
+		}
+	}
+
+	f, err := strconv.ParseFloat(sc.s[start:sc.i], 32)
+	if err != nil {
+		sc.i = start
+		return 0, sc.errorf("invalid number")
+	}
+	return float32(f), nil
+}
+
+// flag reads an arc flag, which is a single 0 or 1 that needn't be followed by a separator.
+func (sc *svgScanner) flag() (bool, error) {
+	sc.skipSeparators()
+	if sc.i < len(sc.s) {
+		switch sc.s[sc.i] {
+		case '0':
+			sc.i++
+			return false, nil
+		case '1':
+			sc.i++
+			return true, nil
+		}
+	}
+	return false, sc.errorf("expected a flag (0 or 1)")
+}
+
+// svgUnits is the number of pixels in each absolute unit.
+var svgUnits = map[string]float32{
+	"":   1,
+	"px": 1,
+	"in": 96,
+	"cm": 96 / 2.54,
+	"mm": 96 / 25.4,
+	"pt": 96.0 / 72,
+	"pc": 16,
+}
+
+// length reads a number with an optional absolute unit, and returns it in pixels.
+func (sc *svgScanner) length() (float32, error) {
+	n, err := sc.number()
+	if err != nil {
+		return 0, err
+	}
+	start := sc.i
+	for sc.i < len(sc.s) && ((sc.s[sc.i] >= 'a' && sc.s[sc.i] <= 'z') || sc.s[sc.i] == '%') {
+		sc.i++
+	}
+	unit := sc.s[start:sc.i]
+	k, ok := svgUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unit %q is not supported", unit)
+	}
+	return n * k, nil
+}
+
+func parseSVGNumbers(v string) ([]float32, error) {
+	sc := svgScanner{s: v}
+	var out []float32
+	for !sc.done() {
+		n, err := sc.number()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// parseSVGNumber parses v, which must be a single number.
+func parseSVGNumber(v string) (float32, error) {
+	sc := svgScanner{s: v}
+	n, err := sc.number()
+	if err != nil {
+		return 0, err
+	}
+	if !sc.done() {
+		return 0, sc.errorf("unexpected text")
+	}
+	return n, nil
+}
+
+// parseSVGLength parses v, which must be a single length (see svgScanner.length).
+func parseSVGLength(v string) (float32, error) {
+	sc := svgScanner{s: v}
+	n, err := sc.length()
+	if err != nil {
+		return 0, err
+	}
+	if !sc.done() {
+		return 0, sc.errorf("unexpected text")
+	}
+	return n, nil
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseSVG(t *testing.T) {
+	// trimmed down from an Inkscape file
+	const doc = `<?xml version="1.0" encoding="UTF-8"?>
+<svg width="100mm" height="100mm" viewBox="10 20 100 50"
+   xmlns="http://www.w3.org/2000/svg"
+   xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"
+   xmlns:sodipodi="http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd">
+  <sodipodi:namedview id="namedview1" pagecolor="#ffffff" />
+  <defs id="defs1" />
+  <g inkscape:label="Layer 1" inkscape:groupmode="layer" transform="translate(5,0)" style="fill:#ff0000">
+    <path style="stroke:#00f;stroke-width:2;stroke-linejoin:round" d="M 10,20 h 10 v 10 z" />
+    <rect x="10" y="20" width="4" height="4" fill="none" stroke="black" opacity="0.5" />
+    <circle cx="50" cy="50" r="5" style="display:none" />
+  </g>
+  <polygon points="0,0 1,0 1,1" fill="rgb(0, 128, 255)" fill-rule="evenodd" />
+</svg>`
+
+	img, err := ParseSVG(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 100 || img.Height != 50 {
+		t.Errorf("expected size 100x50, got %vx%v", img.Width, img.Height)
+	}
+	if len(img.Shapes) != 3 {
+		t.Fatalf("expected 3 shapes (the hidden circle is skipped), got %d", len(img.Shapes))
+	}
+
+	path := img.Shapes[0]
+	if path.Fill != (SolidPaint{Color: color.NRGBA{0xff, 0, 0, 0xff}}) {
+		t.Errorf("expected fill inherited from group, got %v", path.Fill)
+	}
+	if path.Color != (color.NRGBA{0, 0, 0xff, 0xff}) || path.StrokeWidth != 2 {
+		t.Errorf("expected 2px blue stroke, got %v %v", path.StrokeWidth, path.Color)
+	}
+	// the viewBox's top-left is moved to the origin, after the group's transform
+	m := path.Xfm.Matrix()
+	assertVec(t, m.MultiplyVec2D(Vec2D{10, 20}), Vec2D{5, 0})
+	cs := path.Path.Flatten(0.1)
+	if len(cs) != 1 || !cs[0].Closed || len(cs[0].Points) != 3 {
+		t.Errorf("expected a closed triangle, got %+v", cs)
+	}
+
+	rect := img.Shapes[1]
+	if rect.Fill != nil {
+		t.Errorf("expected no fill, got %v", rect.Fill)
+	}
+	if rect.Color != (color.NRGBA{0, 0, 0, 0x80}) || rect.StrokeWidth != 1 {
+		t.Errorf("expected half transparent 1px black stroke, got %v %v", rect.StrokeWidth, rect.Color)
+	}
+
+	poly := img.Shapes[2]
+	if poly.Fill != (SolidPaint{Color: color.NRGBA{0, 128, 255, 0xff}}) || poly.FillRule != FillRuleEvenOdd {
+		t.Errorf("unexpected fill %v %v", poly.Fill, poly.FillRule)
+	}
+	if poly.Color != nil {
+		t.Errorf("expected no stroke, got %v", poly.Color)
+	}
+}
+
+func TestParseSVG_Transforms(t *testing.T) {
+	const doc = `<svg xmlns="http://www.w3.org/2000/svg">
+  <g transform="scale(2)">
+    <line x1="0" y1="0" x2="1" y2="0" stroke="red" stroke-width="3" stroke-dasharray="1 2"
+      transform="translate(10 0) rotate(90)" />
+  </g>
+</svg>`
+
+	img, err := ParseSVG(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := img.Shapes[0]
+	m := line.Xfm.Matrix()
+	assertVec(t, m.MultiplyVec2D(Vec2D{0, 0}), Vec2D{20, 0})
+	assertVec(t, m.MultiplyVec2D(Vec2D{1, 0}), Vec2D{20, 2})
+	// widths and dashes are in pixels, so they're scaled up front
+	assertNear(t, "stroke width", line.StrokeWidth, 6)
+	if len(line.Stroke.Dashes) != 2 {
+		t.Fatalf("expected 2 dashes, got %v", line.Stroke.Dashes)
+	}
+	assertNear(t, "dash", line.Stroke.Dashes[0], 2)
+	assertNear(t, "gap", line.Stroke.Dashes[1], 4)
+}
+
+func TestParseSVG_Errors(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Body     string
+		Expected string
+	}{
+		{"element", `<text>hi</text>`, "line 1: <text>: unsupported element"},
+		{"gradient", `<rect width="1" height="1" fill="url(#grad)" />`, "fill: gradients and patterns"},
+		{"filter", `<g filter="url(#blur)"></g>`, "filter is not supported"},
+		{"color", `<rect width="1" height="1" fill="octarine" />`, `unknown color "octarine"`},
+		{"unit", `<rect width="1" height="1" stroke-width="1em" />`, `unit "em" is not supported`},
+		{"transform", `<g transform="perspective(1)"></g>`, `unknown transform "perspective"`},
+		{"path", `<path d="M 0 0 L 1" />`, "d: L: expected a number at end of input"},
+		{"points", `<polygon points="0 0 1" />`, "odd number of coordinates"},
+		{"nested", `<svg></svg>`, "nested <svg>"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			doc := `<svg xmlns="http://www.w3.org/2000/svg">` + tc.Body + `</svg>`
+			_, err := ParseSVG(strings.NewReader(doc))
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.Expected) {
+				t.Errorf("expected error containing %q, got %q", tc.Expected, err)
+			}
+		})
+	}
+}
+
+func TestParseSVGPathData(t *testing.T) {
+	cases := []struct {
+		Name     string
+		D        string
+		Expected []Contour
+	}{
+		{"absolute", "M0 0 L10 0 L10 10 Z", []Contour{{[]Vec2D{{0, 0}, {10, 0}, {10, 10}}, true}}},
+		{"relative", "m1 1 l9 0 0 9 z", []Contour{{[]Vec2D{{1, 1}, {10, 1}, {10, 10}}, true}}},
+		{"implicit lineto", "M0 0 10 0 10 10", []Contour{{[]Vec2D{{0, 0}, {10, 0}, {10, 10}}, false}}},
+		{"horizontal and vertical", "M0,0H5V5h-5v-2", []Contour{{[]Vec2D{{0, 0}, {5, 0}, {5, 5}, {0, 5}, {0, 3}}, false}}},
+		{"compact numbers", "M0-1.5.5 0L1e1,2", []Contour{{[]Vec2D{{0, -1.5}, {0.5, 0}, {10, 2}}, false}}},
+		{"subpaths", "M0 0h1v1z m2 0 h1 v1z", []Contour{
+			{[]Vec2D{{0, 0}, {1, 0}, {1, 1}}, true},
+			{[]Vec2D{{2, 0}, {3, 0}, {3, 1}}, true},
+		}},
+		{"zero radius arc", "M0 0 A0 5 0 0 1 5 5", []Contour{{[]Vec2D{{0, 0}, {5, 5}}, false}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var p Path
+			if err := parseSVGPathData(&p, tc.D); err != nil {
+				t.Fatal(err)
+			}
+			cs := p.Flatten(0.1)
+			if len(cs) != len(tc.Expected) {
+				t.Fatalf("expected %d contours, got %d", len(tc.Expected), len(cs))
+			}
+			for i := range cs {
+				if cs[i].Closed != tc.Expected[i].Closed || len(cs[i].Points) != len(tc.Expected[i].Points) {
+					t.Fatalf("contour %d: expected %v, got %v", i, tc.Expected[i], cs[i])
+				}
+				for j := range cs[i].Points {
+					assertVec(t, cs[i].Points[j], tc.Expected[i].Points[j])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSVGPathData_Arc(t *testing.T) {
+	// the two halves of a circle, using flags without separators
+	var p Path
+	if err := parseSVGPathData(&p, "M10 0a10 10 0 1020 0 10 10 0 10-20 0"); err != nil {
+		t.Fatal(err)
+	}
+	cs := p.Flatten(0.01)
+	if len(cs) != 1 {
+		t.Fatalf("expected 1 contour, got %d", len(cs))
+	}
+	pts := cs[0].Points
+	for _, v := range pts {
+		if math.Abs(float64(v.Distance(Vec2D{20, 0})-10)) > 0.01 {
+			t.Errorf("point %v is not on the circle", v)
+		}
+	}
+	assertVec(t, pts[len(pts)/2], Vec2D{30, 0})
+	assertVec(t, pts[len(pts)-1], Vec2D{10, 0})
+}
+
+func TestParseSVGPathData_Smooth(t *testing.T) {
+	// smooth curves reflect the previous control point, so they match the explicit version
+	cases := []struct {
+		Name     string
+		D        string
+		Expected string
+	}{
+		{"cubic", "M0 0 C0 10 10 10 10 0 S20 -10 20 0", "M0 0 C0 10 10 10 10 0 C10 -10 20 -10 20 0"},
+		{"relative cubic", "m0 0 c0 10 10 10 10 0 s10 -10 10 0", "M0 0 C0 10 10 10 10 0 C10 -10 20 -10 20 0"},
+		{"cubic after line", "M0 0 L10 0 S20 -10 20 0", "M0 0 L10 0 C10 0 20 -10 20 0"},
+		{"quad", "M0 0 Q5 10 10 0 T20 0", "M0 0 Q5 10 10 0 Q15 -10 20 0"},
+		{"quad chain", "M0 0 Q5 10 10 0 T20 0 T30 0", "M0 0 Q5 10 10 0 Q15 -10 20 0 Q25 10 30 0"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var p, expected Path
+			if err := parseSVGPathData(&p, tc.D); err != nil {
+				t.Fatal(err)
+			}
+			if err := parseSVGPathData(&expected, tc.Expected); err != nil {
+				t.Fatal(err)
+			}
+			a, b := p.Flatten(0.1)[0].Points, expected.Flatten(0.1)[0].Points
+			if len(a) != len(b) {
+				t.Fatalf("expected %d points, got %d", len(b), len(a))
+			}
+			for i := range a {
+				assertVec(t, a[i], b[i])
+			}
+		})
+	}
+}
+
+func TestParseSVGColor(t *testing.T) {
+	cases := []struct {
+		In       string
+		Expected color.NRGBA
+	}{
+		{"#f80", color.NRGBA{0xff, 0x88, 0x00, 0xff}},
+		{"#F80C", color.NRGBA{0xff, 0x88, 0x00, 0xcc}},
+		{"#123456", color.NRGBA{0x12, 0x34, 0x56, 0xff}},
+		{"#12345678", color.NRGBA{0x12, 0x34, 0x56, 0x78}},
+		{"rgb(1,2,3)", color.NRGBA{1, 2, 3, 0xff}},
+		{"rgba(1, 2, 3, 0.5)", color.NRGBA{1, 2, 3, 0x80}},
+		{"rgb(100% 0% 50%)", color.NRGBA{0xff, 0, 0x80, 0xff}},
+		{"Orange", color.NRGBA{0xff, 0xa5, 0x00, 0xff}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.In, func(t *testing.T) {
+			c, err := parseSVGColor(tc.In)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c != tc.Expected {
+				t.Errorf("expected %v, got %v", tc.Expected, c)
+			}
+		})
+	}
+
+	for _, bad := range []string{"#12", "#ggg", "rgb(1,2)", "hsl(0, 0%, 0%)"} {
+		if _, err := parseSVGColor(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestParseSVGTransform(t *testing.T) {
+	cases := []struct {
+		In       string
+		Expected Matrix3x3
+	}{
+		{"", Identity3x3()},
+		{"translate(3)", Translation3x3(3, 0)},
+		{"scale(2)", Scale3x3(2, 2)},
+		{"matrix(1 2 3 4 5 6)", Matrix3x3{1, 3, 5, 2, 4, 6, 0, 0, 1}},
+		{"translate(1,2) scale(3,4)", Translation3x3(1, 2).Multiply(Scale3x3(3, 4))},
+		{"rotate(90 10 0)", Matrix3x3{0, -1, 10, 1, 0, -10, 0, 0, 1}},
+		{"skewX(45)", Shear3x3(1, 0)},
+		{"skewY(45)", Shear3x3(0, 1)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.In, func(t *testing.T) {
+			m, err := parseSVGTransform(tc.In)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertMatrixNear(t, m, tc.Expected)
+		})
+	}
+}
+
+func TestParseSVGLength(t *testing.T) {
+	cases := []struct {
+		In       string
+		Expected float32
+	}{
+		{"12", 12},
+		{"12px", 12},
+		{"1in", 96},
+		{"25.4mm", 96},
+		{"-1.5e1", -15},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.In, func(t *testing.T) {
+			n, err := parseSVGLength(tc.In)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertNear(t, tc.In, n, tc.Expected)
+		})
+	}
+
+	for _, bad := range []string{"", "px", "50%", "1 2"} {
+		if _, err := parseSVGLength(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// DrawText draws s onto dst, laid out as style says, with the point of the
+// text given by style.Anchor placed at pos.
+func DrawText(dst *ebiten.Image, s string, pos Vec2D, style TextStyle) {
+	face := style.face()
+	clr := style.color()
+	l := layoutText(s, style)
+	for i, line := range l.Lines {
+		// snap to whole pixels, so glyphs stay sharp
+		p := l.lineOrigin(i, pos, style)
+		text.Draw(dst, line.Text, face, int(math.Round(float64(p.X))), int(math.Round(float64(p.Y))), clr)
+	}
+}
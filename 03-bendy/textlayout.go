@@ -0,0 +1,172 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Align decides how the lines in a block of text line up with each other.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Anchors for TextStyle, naming points on the edge of a block of text.
+var (
+	AnchorTopLeft     = Vec2D{0, 0}
+	AnchorTop         = Vec2D{0.5, 0}
+	AnchorTopRight    = Vec2D{1, 0}
+	AnchorLeft        = Vec2D{0, 0.5}
+	AnchorCenter      = Vec2D{0.5, 0.5}
+	AnchorRight       = Vec2D{1, 0.5}
+	AnchorBottomLeft  = Vec2D{0, 1}
+	AnchorBottom      = Vec2D{0.5, 1}
+	AnchorBottomRight = Vec2D{1, 1}
+)
+
+// defaultTextSize is the size (in pixels) of text whose style doesn't set one.
+const defaultTextSize = 16
+
+// TextStyle decides how text is laid out and drawn. The zero value draws
+// white, left aligned text in the default font, without wrapping, with its
+// top-left corner at the position it's drawn at.
+type TextStyle struct {
+	Font  *Font       // nil for DefaultFont
+	Size  float64     // in pixels; 0 for defaultTextSize
+	Color color.Color // nil for white
+	Align Align
+
+	// Anchor is the point of the block of text that's placed at the
+	// position it's drawn at, as a fraction of the block's size, from (0,0)
+	// at the top-left to (1,1) at the bottom-right.
+	Anchor Vec2D
+
+	// Width is how wide lines can get before being wrapped (at spaces if
+	// possible). If it's set, it's also the width of the block, which lines
+	// are aligned within. 0 for no wrapping.
+	Width float32
+
+	LineSpacing float32 // distance between lines, as a multiple of the font's line height; 0 for 1
+}
+
+func (s TextStyle) face() font.Face {
+	f := s.Font
+	if f == nil {
+		f = DefaultFont()
+	}
+	size := s.Size
+	if size <= 0 {
+		size = defaultTextSize
+	}
+	return f.Face(size)
+}
+
+func (s TextStyle) color() color.Color {
+	if s.Color == nil {
+		return color.White
+	}
+	return s.Color
+}
+
+// textLine is one line of a textLayout.
+type textLine struct {
+	Text  string
+	Width float32
+}
+
+// textLayout is a block of text broken into lines.
+type textLayout struct {
+	Lines      []textLine
+	Size       Vec2D
+	LineHeight float32 // from one baseline to the next
+	Ascent     float32 // from the top of a line to its baseline
+}
+
+// layoutText breaks s into lines at newlines, and wherever it's wider than
+// style.Width.
+func layoutText(s string, style TextStyle) textLayout {
+	face := style.face()
+	metrics := face.Metrics()
+	spacing := style.LineSpacing
+	if spacing <= 0 {
+		spacing = 1
+	}
+
+	l := textLayout{
+		LineHeight: fixedToFloat32(metrics.Height) * spacing,
+		Ascent:     fixedToFloat32(metrics.Ascent),
+	}
+	for _, para := range strings.Split(s, "\n") {
+		l.Lines = wrapText(l.Lines, face, para, style.Width)
+	}
+
+	l.Size.X = style.Width
+	if l.Size.X <= 0 {
+		for _, line := range l.Lines {
+			l.Size.X = max32(l.Size.X, line.Width)
+		}
+	}
+	l.Size.Y = float32(len(l.Lines)-1)*l.LineHeight + fixedToFloat32(metrics.Height)
+	return l
+}
+
+// wrapText appends para to lines, broken into as many lines as it takes for
+// none to be wider than maxWidth (if it's positive). Lines are broken at the
+// last space that fits, or mid-word if a word is too long to fit by itself.
+func wrapText(lines []textLine, face font.Face, para string, maxWidth float32) []textLine {
+	measure := func(s string) float32 {
+		return fixedToFloat32(font.MeasureString(face, s))
+	}
+	if maxWidth <= 0 {
+		return append(lines, textLine{para, measure(para)})
+	}
+
+	start, space := 0, -1
+	for i := 0; i < len(para); {
+		r, n := utf8.DecodeRuneInString(para[i:])
+		if r == ' ' {
+			space = i
+		}
+		if i > start && measure(para[start:i+n]) > maxWidth {
+			end, next := i, i
+			if space > start {
+				end, next = space, space+1
+			}
+			lines = append(lines, textLine{para[start:end], measure(para[start:end])})
+			start = next
+			continue // see if this rune fits on the new line
+		}
+		i += n
+	}
+	return append(lines, textLine{para[start:], measure(para[start:])})
+}
+
+// lineOrigin returns where line i starts on its baseline, when the block of
+// text is drawn at pos.
+func (l textLayout) lineOrigin(i int, pos Vec2D, style TextStyle) Vec2D {
+	topLeft := pos.Sub(Vec2D{l.Size.X * style.Anchor.X, l.Size.Y * style.Anchor.Y})
+	x := topLeft.X
+	switch style.Align {
+	case AlignCenter:
+		x += (l.Size.X - l.Lines[i].Width) / 2
+	case AlignRight:
+		x += l.Size.X - l.Lines[i].Width
+	}
+	return Vec2D{x, topLeft.Y + float32(i)*l.LineHeight + l.Ascent}
+}
+
+// MeasureText returns the size of the block of text that DrawText would draw.
+func MeasureText(s string, style TextStyle) Vec2D {
+	return layoutText(s, style).Size
+}
+
+func fixedToFloat32(x fixed.Int26_6) float32 {
+	return float32(x) / 64
+}
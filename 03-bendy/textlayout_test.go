@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLayoutText(t *testing.T) {
+	style := TextStyle{Size: 20}
+	one := layoutText("Spin", style)
+	if len(one.Lines) != 1 || one.Lines[0].Width <= 0 {
+		t.Fatalf("unexpected layout of one line: %+v", one)
+	}
+	assertNear(t, "width", one.Size.X, one.Lines[0].Width)
+
+	two := layoutText("Spin\nthe dial", style)
+	if len(two.Lines) != 2 || two.Lines[1].Text != "the dial" {
+		t.Fatalf("unexpected lines %+v", two.Lines)
+	}
+	assertNear(t, "height", two.Size.Y, one.Size.Y+one.LineHeight)
+
+	style.LineSpacing = 2
+	spaced := layoutText("Spin\nthe dial", style)
+	assertNear(t, "spaced height", spaced.Size.Y, one.Size.Y+2*one.LineHeight)
+}
+
+func TestWrapText(t *testing.T) {
+	face := TextStyle{}.face()
+	width := MeasureText("the dial", TextStyle{})
+
+	cases := []struct {
+		Name   string
+		Text   string
+		Expect []string
+	}{
+		{"fits", "the dial", []string{"the dial"}},
+		{"at spaces", "the dial the dial the", []string{"the dial", "the dial", "the"}},
+		{"long word", "abcdefghijklmnopqrstuvwxyz", nil},
+		{"empty", "", []string{""}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			lines := wrapText(nil, face, tc.Text, width.X)
+			var got []string
+			for _, l := range lines {
+				if l.Width > width.X {
+					t.Errorf("line %q is wider than %v: %v", l.Text, width.X, l.Width)
+				}
+				got = append(got, l.Text)
+			}
+			if tc.Expect == nil {
+				// broken mid-word, without losing anything
+				if len(got) < 2 || strings.Join(got, "") != tc.Text {
+					t.Errorf("expected %q to be broken up, got %q", tc.Text, got)
+				}
+			} else if strings.Join(got, "|") != strings.Join(tc.Expect, "|") {
+				t.Errorf("expected %q, got %q", tc.Expect, got)
+			}
+		})
+	}
+}
+
+func TestTextLayout_LineOrigin(t *testing.T) {
+	style := TextStyle{Align: AlignRight, Anchor: AnchorBottomRight}
+	l := layoutText("a\nlonger line", style)
+	pos := Vec2D{100, 200}
+
+	// the widest line ends at pos.X, and so does the shorter one when right aligned
+	for i, line := range l.Lines {
+		o := l.lineOrigin(i, pos, style)
+		assertNear(t, "line end", o.X+line.Width, 100)
+	}
+	// the last line's box ends at pos.Y
+	last := l.lineOrigin(1, pos, style)
+	assertNear(t, "bottom", last.Y-l.Ascent+l.Size.Y-l.LineHeight, 200)
+
+	style = TextStyle{Align: AlignCenter, Anchor: AnchorTopLeft, Width: 300}
+	l = layoutText("centered", style)
+	o := l.lineOrigin(0, pos, style)
+	assertNear(t, "centered", o.X-pos.X, (300-l.Lines[0].Width)/2)
+	assertNear(t, "baseline", o.Y, pos.Y+l.Ascent)
+}
+
+func TestParseFont(t *testing.T) {
+	if _, err := ParseFont([]byte("not a font")); err == nil {
+		t.Errorf("expected an error")
+	}
+	f := DefaultFont()
+	if f.Face(12) != f.Face(12) {
+		t.Errorf("expected faces to be reused")
+	}
+}
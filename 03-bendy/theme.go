@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+)
+
+// Theme is a palette of named colors that the game is drawn with.
+type Theme struct {
+	Name       string     `json:"name"`
+	Background ThemeColor `json:"background"`
+	Foreground ThemeColor `json:"foreground"` // the dial and text
+	Highlight  ThemeColor `json:"highlight"`  // text that should stand out, like "CLICK!"
+	Success    ThemeColor `json:"success"`    // what the dial is tinted when it's turned to the goal
+	Error      ThemeColor `json:"error"`
+}
+
+// DefaultTheme returns the theme the game is drawn with when no other is chosen.
+func DefaultTheme() Theme {
+	return Theme{
+		Name:       "default",
+		Background: ThemeColor{0x56, 0x55, 0x54, 0xff},
+		Foreground: ThemeColor{0xf6, 0xf1, 0x93, 0xee},
+		Highlight:  ThemeColor{0xff, 0xff, 0xff, 0xff},
+		Success:    ThemeColor{0x8c, 0xe9, 0x9a, 0xff},
+		Error:      ThemeColor{0xff, 0x80, 0x70, 0xff},
+	}
+}
+
+// BuiltinThemes returns the themes that are available without being added to
+// the config file, starting with DefaultTheme.
+func BuiltinThemes() []Theme {
+	return []Theme{
+		DefaultTheme(),
+		{
+			Name:       "light",
+			Background: ThemeColor{0xf4, 0xf1, 0xe8, 0xff},
+			Foreground: ThemeColor{0x3a, 0x39, 0x36, 0xff},
+			Highlight:  ThemeColor{0xd9, 0x48, 0x0f, 0xff},
+			Success:    ThemeColor{0x2b, 0x8a, 0x3e, 0xff},
+			Error:      ThemeColor{0xc9, 0x2a, 0x2a, 0xff},
+		},
+	}
+}
+
+// UnmarshalJSON starts from DefaultTheme, so a theme in a config file only
+// needs the colors it changes.
+func (t *Theme) UnmarshalJSON(data []byte) error {
+	type plain Theme // without this method, to avoid recursing
+	th := plain(DefaultTheme())
+	th.Name = ""
+	if err := json.Unmarshal(data, &th); err != nil {
+		return err
+	}
+	*t = Theme(th)
+	return nil
+}
+
+// Lerp blends each of the theme's colors toward o's, from t (at f=0) to o
+// (at f=1). The result is named after o.
+func (t Theme) Lerp(o Theme, f float32) Theme {
+	return Theme{
+		Name:       o.Name,
+		Background: lerpThemeColor(t.Background, o.Background, f),
+		Foreground: lerpThemeColor(t.Foreground, o.Foreground, f),
+		Highlight:  lerpThemeColor(t.Highlight, o.Highlight, f),
+		Success:    lerpThemeColor(t.Success, o.Success, f),
+		Error:      lerpThemeColor(t.Error, o.Error, f),
+	}
+}
+
+// ThemeColor is a color with straight (not premultiplied) alpha. In config
+// files it's a string, written the same ways as SVG colors, e.g. "#f6f193",
+// "#f6f193ee", "rgb(246 241 147)" or "orange".
+type ThemeColor color.NRGBA
+
+func (c ThemeColor) RGBA() (r, g, b, a uint32) {
+	return color.NRGBA(c).RGBA()
+}
+
+func (c ThemeColor) MarshalJSON() ([]byte, error) {
+	s := fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	if c.A != 0xff {
+		s += fmt.Sprintf("%02x", c.A)
+	}
+	return json.Marshal(s)
+}
+
+func (c *ThemeColor) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	clr, err := parseSVGColor(s)
+	if err != nil {
+		return err
+	}
+	*c = ThemeColor(color.NRGBAModel.Convert(clr).(color.NRGBA))
+	return nil
+}
+
+// LerpColor blends from a (at t=0) to b (at t=1), clamping t to [0,1]. Colors
+// are blended with premultiplied alpha, so fading from a transparent color
+// doesn't darken the other one.
+func LerpColor(a, b color.Color, t float32) color.RGBA {
+	t = min32(max32(t, 0), 1)
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	mix := func(x, y uint32) uint8 {
+		return uint8(lerp32(float32(x), float32(y), t)/0x101 + 0.5)
+	}
+	return color.RGBA{mix(ar, br), mix(ag, bg), mix(ab, bb), mix(aa, ba)}
+}
+
+func lerpThemeColor(a, b ThemeColor, t float32) ThemeColor {
+	return ThemeColor(color.NRGBAModel.Convert(LerpColor(a, b, t)).(color.NRGBA))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"image/color"
+	"testing"
+)
+
+func TestLerpColor(t *testing.T) {
+	cases := []struct {
+		Name     string
+		A, B     color.Color
+		T        float32
+		Expected color.RGBA
+	}{
+		{"start", color.RGBA{0, 0, 0, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}, 0, color.RGBA{0, 0, 0, 0xff}},
+		{"end", color.RGBA{0, 0, 0, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}, 1, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{"middle", color.RGBA{0, 0x40, 0x80, 0xff}, color.RGBA{0x80, 0x40, 0, 0xff}, 0.5, color.RGBA{0x40, 0x40, 0x40, 0xff}},
+		{"clamped low", color.RGBA{0x10, 0x20, 0x30, 0xff}, color.White, -1, color.RGBA{0x10, 0x20, 0x30, 0xff}},
+		{"clamped high", color.Black, color.RGBA{0x10, 0x20, 0x30, 0xff}, 2, color.RGBA{0x10, 0x20, 0x30, 0xff}},
+		// premultiplied, so the transparent end doesn't pull the color toward black
+		{"from transparent", color.Transparent, color.NRGBA{0xff, 0, 0, 0xff}, 0.5, color.RGBA{0x80, 0, 0, 0x80}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			actual := LerpColor(tc.A, tc.B, tc.T)
+			if actual != tc.Expected {
+				t.Errorf("expected %v, got %v", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestThemeColor_JSON(t *testing.T) {
+	cases := []struct {
+		Name     string
+		JSON     string
+		Expected ThemeColor
+		Output   string // when marshaled back
+	}{
+		{"hex", `"#F6F193"`, ThemeColor{0xf6, 0xf1, 0x93, 0xff}, `"#f6f193"`},
+		{"hex alpha", `"#f6f193ee"`, ThemeColor{0xf6, 0xf1, 0x93, 0xee}, `"#f6f193ee"`},
+		{"short hex", `"#f80"`, ThemeColor{0xff, 0x88, 0x00, 0xff}, `"#ff8800"`},
+		{"named", `"orange"`, ThemeColor{0xff, 0xa5, 0x00, 0xff}, `"#ffa500"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var c ThemeColor
+			if err := json.Unmarshal([]byte(tc.JSON), &c); err != nil {
+				t.Fatal(err)
+			}
+			if c != tc.Expected {
+				t.Errorf("expected %v, got %v", tc.Expected, c)
+			}
+			b, err := json.Marshal(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.Output {
+				t.Errorf("expected %s, got %s", tc.Output, b)
+			}
+		})
+	}
+
+	var c ThemeColor
+	if err := json.Unmarshal([]byte(`"#nothex"`), &c); err == nil {
+		t.Errorf("expected an error for an invalid color")
+	}
+}
+
+func TestTheme_UnmarshalStartsFromDefault(t *testing.T) {
+	var th Theme
+	if err := json.Unmarshal([]byte(`{"name": "dusk", "background": "#102030"}`), &th); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := DefaultTheme()
+	expected.Name = "dusk"
+	expected.Background = ThemeColor{0x10, 0x20, 0x30, 0xff}
+	if th != expected {
+		t.Errorf("expected %+v, got %+v", expected, th)
+	}
+}
+
+func TestTheme_Lerp(t *testing.T) {
+	themes := BuiltinThemes()
+	dark, light := themes[0], themes[1]
+
+	if th := dark.Lerp(light, 0); th.Background != dark.Background || th.Name != light.Name {
+		t.Errorf("expected dark colors named %s, got %+v", light.Name, th)
+	}
+	if th := dark.Lerp(light, 1); th != light {
+		t.Errorf("expected %+v, got %+v", light, th)
+	}
+	th := dark.Lerp(light, 0.5)
+	expected := ThemeColor(color.NRGBAModel.Convert(LerpColor(dark.Success, light.Success, 0.5)).(color.NRGBA))
+	if th.Success != expected {
+		t.Errorf("expected %v, got %v", expected, th.Success)
+	}
+}
+
+func TestConfig_SelectTheme(t *testing.T) {
+	cfg := Config{
+		Themes: []Theme{
+			{Name: "light", Background: ThemeColor{0xff, 0xff, 0xff, 0xff}},
+			{Name: "dusk"},
+		},
+		Theme: "dusk",
+	}
+
+	cases := []struct {
+		Name     string
+		Config   Config
+		Select   string
+		Expected string
+		Err      bool
+	}{
+		{"default", Config{}, "", "default", false},
+		{"builtin", Config{}, "light", "light", false},
+		{"config's choice", cfg, "", "dusk", false},
+		{"overrides config's choice", cfg, "default", "default", false},
+		{"missing", cfg, "neon", "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			th, err := tc.Config.SelectTheme(tc.Select)
+			if tc.Err {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", th)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if th.Name != tc.Expected {
+				t.Errorf("expected %s, got %s", tc.Expected, th.Name)
+			}
+		})
+	}
+
+	// config themes replace built-in ones with the same name, and are added after the rest
+	all := cfg.AllThemes()
+	if len(all) != 3 || all[1].Background != cfg.Themes[0].Background || all[2].Name != "dusk" {
+		t.Errorf("expected default, the config's light and dusk, got %+v", all)
+	}
+}
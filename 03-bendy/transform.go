@@ -1,17 +1,26 @@
 package main
 
-import "math"
-
-type Vec2D struct {
-	X, Y float32
-}
-
+// Transform2D places a shape in the world. Points are scaled, sheared, and
+// rotated around the pivot (in the shape's local space), and then moved so
+// that the pivot lands on the position.
 type Transform2D struct {
 	position    Vec2D
 	rotation    float32
-	scale       float32
+	scale       Vec2D
+	shear       Vec2D
+	pivot       Vec2D
 	matrix      Matrix3x3
 	needsUpdate bool
+	version     uint64 // incremented on every change, so anything caching the matrix knows to update
+}
+
+// NewTransform2D returns a transform with a scale of 1 that leaves points unchanged.
+func NewTransform2D() Transform2D {
+	return Transform2D{
+		scale:       Vec2D{1, 1},
+		matrix:      Identity3x3(),
+		needsUpdate: false,
+	}
 }
 
 func (t *Transform2D) Pos() Vec2D {
@@ -22,10 +31,18 @@ func (t *Transform2D) Rot() float32 {
 	return t.rotation
 }
 
-func (t *Transform2D) Scale() float32 {
+func (t *Transform2D) Scale() Vec2D {
 	return t.scale
 }
 
+func (t *Transform2D) Shear() Vec2D {
+	return t.shear
+}
+
+func (t *Transform2D) Pivot() Vec2D {
+	return t.pivot
+}
+
 func (t *Transform2D) Matrix() Matrix3x3 {
 	if t.needsUpdate {
 		t.needsUpdate = false
@@ -37,31 +54,56 @@ func (t *Transform2D) Matrix() Matrix3x3 {
 func (t *Transform2D) SetPos(p Vec2D) {
 	t.position = p
 	t.needsUpdate = true
+	t.version++
 }
 
 func (t *Transform2D) SetRot(r float32) {
 	t.rotation = r
 	t.needsUpdate = true
+	t.version++
 }
 
-func (t *Transform2D) SetScale(s float32) {
+func (t *Transform2D) SetScale(s Vec2D) {
 	t.scale = s
 	t.needsUpdate = true
+	t.version++
+}
+
+func (t *Transform2D) SetUniformScale(s float32) {
+	t.SetScale(Vec2D{s, s})
+}
+
+// SetShear sets the shear factors (see Shear3x3).
+func (t *Transform2D) SetShear(s Vec2D) {
+	t.shear = s
+	t.needsUpdate = true
+	t.version++
+}
+
+// SetPivot sets the point (in local space) that the transform scales, shears, and rotates around.
+func (t *Transform2D) SetPivot(p Vec2D) {
+	t.pivot = p
+	t.needsUpdate = true
+	t.version++
+}
+
+// SetMatrix sets the position, rotation, scale, and shear to rebuild the
+// affine matrix m (see Matrix3x3.Decompose), and moves the pivot to the origin.
+func (t *Transform2D) SetMatrix(m Matrix3x3) {
+	pos, rot, scale, shear := m.Decompose()
+	t.position = pos
+	t.rotation = rot
+	t.scale = scale
+	t.shear = Vec2D{shear, 0}
+	t.pivot = Vec2D{}
+	t.needsUpdate = true
+	t.version++
 }
 
 func (t *Transform2D) calcMatrix() Matrix3x3 {
-	var m Matrix3x3
-	theta := float64(t.rotation)
-	c := float32(math.Cos(theta))
-	s := float32(math.Sin(theta))
-	m[0] = c * t.scale
-	m[1] = -s * t.scale
-	m[2] = t.position.X
-	m[3] = s * t.scale
-	m[4] = c * t.scale
-	m[5] = t.position.Y
-	m[6] = 0
-	m[7] = 0
-	m[8] = 1
-	return m
+	return Translation3x3(t.position.X, t.position.Y).
+		Multiply(Rotation3x3(t.rotation)).
+		Multiply(Shear3x3(t.shear.X, t.shear.Y)).
+		Multiply(Scale3x3(t.scale.X, t.scale.Y)).
+		Multiply(Translation3x3(-t.pivot.X, -t.pivot.Y))
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransform2D_Identity(t *testing.T) {
+	xfm := NewTransform2D()
+	assertMatrix(t, xfm.Matrix(), Identity3x3())
+}
+
+func TestTransform2D_Pivot(t *testing.T) {
+	xfm := NewTransform2D()
+	xfm.SetPivot(Vec2D{10, 0})
+	xfm.SetPos(Vec2D{100, 100})
+	xfm.SetRot(math.Pi / 2)
+	xfm.SetScale(Vec2D{2, 3})
+
+	// the pivot always lands on the position
+	m := xfm.Matrix()
+	assertVec(t, m.MultiplyVec2D(Vec2D{10, 0}), Vec2D{100, 100})
+
+	// other points are scaled, then rotated around the pivot
+	assertVec(t, m.MultiplyVec2D(Vec2D{11, 0}), Vec2D{100, 102})
+	assertVec(t, m.MultiplyVec2D(Vec2D{10, 1}), Vec2D{97, 100})
+}
+
+func TestTransform2D_Shear(t *testing.T) {
+	xfm := NewTransform2D()
+	xfm.SetShear(Vec2D{0.5, 0})
+	xfm.SetUniformScale(2)
+
+	assertVec(t, xfm.Matrix().MultiplyVec2D(Vec2D{1, 1}), Vec2D{3, 2})
+}
+
+func TestTransform2D_SetMatrix(t *testing.T) {
+	m := Translation3x3(4, -2).
+		Multiply(Rotation3x3(1)).
+		Multiply(Shear3x3(0.25, 0.5)).
+		Multiply(Scale3x3(2, -3))
+
+	xfm := NewTransform2D()
+	xfm.SetPivot(Vec2D{10, 10})
+	xfm.SetMatrix(m)
+	assertMatrixNear(t, xfm.Matrix(), m)
+	assertVec(t, xfm.Pivot(), Vec2D{})
+}
+
+func TestTransform2D_MatrixUpdates(t *testing.T) {
+	xfm := NewTransform2D()
+	xfm.SetPos(Vec2D{5, 6})
+	assertMatrix(t, xfm.Matrix(), Translation3x3(5, 6))
+	assertMatrix(t, xfm.Matrix(), Translation3x3(5, 6))
+
+	xfm.SetPos(Vec2D{7, 8})
+	assertMatrix(t, xfm.Matrix(), Translation3x3(7, 8))
+
+	pos, rot, scale, shear := xfm.Matrix().Decompose()
+	assertVec(t, pos, Vec2D{7, 8})
+	assertNear(t, "rot", rot, 0)
+	assertVec(t, scale, Vec2D{1, 1})
+	assertNear(t, "shear", shear, 0)
+}
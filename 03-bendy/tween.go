@@ -0,0 +1,264 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// Easing maps how far through a tween it is (from 0 at the start, to 1 at the
+// end) to how far its value has moved from where it started to where it's
+// going. Easings start at 0 and end at 1, but may overshoot in between.
+type Easing func(t float32) float32
+
+func Linear(t float32) float32 {
+	return t
+}
+
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+func EaseOutQuad(t float32) float32 {
+	return 1 - EaseInQuad(1-t)
+}
+
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return EaseInQuad(2*t) / 2
+	}
+	return 1 - EaseInQuad(2-2*t)/2
+}
+
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+func EaseOutCubic(t float32) float32 {
+	return 1 - EaseInCubic(1-t)
+}
+
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return EaseInCubic(2*t) / 2
+	}
+	return 1 - EaseInCubic(2-2*t)/2
+}
+
+// EaseOutElastic overshoots the end, and wobbles back and forth across it
+// before settling.
+func EaseOutElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	const period = 2 * math.Pi / 3
+	tt := float64(t)
+	return float32(math.Pow(2, -10*tt)*math.Sin((10*tt-0.75)*period)) + 1
+}
+
+// Spring returns an easing that moves like a damped spring let go at the
+// start. damping is how quickly its swings die down (at 6, they're almost
+// gone by the end), and bounces is how many times it swings past the end and
+// back.
+func Spring(damping, bounces float32) Easing {
+	return func(t float32) float32 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+		tt := float64(t)
+		return 1 - float32(math.Exp(-float64(damping)*tt)*math.Cos(2*math.Pi*float64(bounces)*tt))
+	}
+}
+
+// Tween moves a value from where it is when the tween starts, to where it's
+// going, over a number of ticks. Tweens are run by adding them to a Tweens.
+type Tween struct {
+	ticks   int    // how long it takes
+	ease    Easing // nil for Linear
+	begin   func() // called on the first tick, to see where the value starts
+	update  func(t float32)
+	done    func()
+	next    *Tween
+	elapsed int
+	started bool
+	stopped bool
+}
+
+// NewTween returns a tween that calls update every tick, with how far the
+// value should have moved (0 to 1, as eased by ease), and calls begin before
+// the first update.
+func NewTween(ticks int, ease Easing, begin func(), update func(t float32)) *Tween {
+	return &Tween{ticks: ticks, ease: ease, begin: begin, update: update}
+}
+
+// TweenFloat moves *v to to.
+func TweenFloat(v *float32, to float32, ticks int, ease Easing) *Tween {
+	var from float32
+	return NewTween(ticks, ease, func() {
+		from = *v
+	}, func(t float32) {
+		*v = lerp32(from, to, t)
+	})
+}
+
+// TweenColor blends *c into to. Colors can't overshoot, so easings that do
+// (like EaseOutElastic) stop at to until they swing back.
+func TweenColor(c *color.Color, to color.Color, ticks int, ease Easing) *Tween {
+	var from color.Color
+	return NewTween(ticks, ease, func() {
+		from = *c
+		if from == nil {
+			from = color.Transparent
+		}
+	}, func(t float32) {
+		*c = LerpColor(from, to, t)
+	})
+}
+
+// TweenPos moves xfm to the position to.
+func TweenPos(xfm *Transform2D, to Vec2D, ticks int, ease Easing) *Tween {
+	var from Vec2D
+	return NewTween(ticks, ease, func() {
+		from = xfm.Pos()
+	}, func(t float32) {
+		xfm.SetPos(from.Lerp(to, t))
+	})
+}
+
+// TweenScale scales xfm to to.
+func TweenScale(xfm *Transform2D, to Vec2D, ticks int, ease Easing) *Tween {
+	var from Vec2D
+	return NewTween(ticks, ease, func() {
+		from = xfm.Scale()
+	}, func(t float32) {
+		xfm.SetScale(from.Lerp(to, t))
+	})
+}
+
+// TweenRot rotates xfm to the angle to (in radians), turning through every
+// angle in between, so the turn is the same size and direction as the change
+// in angle (e.g. from 0.1 to 2π-0.1 is nearly a full turn forward).
+func TweenRot(xfm *Transform2D, to float32, ticks int, ease Easing) *Tween {
+	return tweenRot(xfm, to, ticks, ease, func(from float32) float32 {
+		return to - from
+	})
+}
+
+// TweenRotShortest rotates xfm to the angle to (in radians), whichever way
+// round is shorter, so turning from just under 2π to 0 is a small step
+// forward rather than a full turn back.
+func TweenRotShortest(xfm *Transform2D, to float32, ticks int, ease Easing) *Tween {
+	return tweenRot(xfm, to, ticks, ease, func(from float32) float32 {
+		return float32(math.Remainder(float64(to-from), 2*math.Pi))
+	})
+}
+
+// tweenRot rotates xfm by delta(from), ending at exactly to.
+func tweenRot(xfm *Transform2D, to float32, ticks int, ease Easing, delta func(from float32) float32) *Tween {
+	var from, d float32
+	return NewTween(ticks, ease, func() {
+		from = xfm.Rot()
+		d = delta(from)
+	}, func(t float32) {
+		if t >= 1 {
+			xfm.SetRot(to) // exactly, rather than an equivalent angle
+			return
+		}
+		xfm.SetRot(from + d*t)
+	})
+}
+
+// OnDone sets a function to call once the tween reaches the end. It isn't
+// called if the tween is stopped first. Returns tw, for chaining.
+func (tw *Tween) OnDone(fn func()) *Tween {
+	tw.done = fn
+	return tw
+}
+
+// Then sets next to start as soon as tw ends, and returns next, so that
+// a.Then(b).Then(c) runs a, b, and c in turn.
+func (tw *Tween) Then(next *Tween) *Tween {
+	tw.next = next
+	return next
+}
+
+// Stop ends the tween where it is, without calling OnDone or starting the
+// tween after it. It's safe to call on a nil tween.
+func (tw *Tween) Stop() {
+	if tw != nil {
+		tw.stopped = true
+	}
+}
+
+// Done reports whether the tween has ended or was stopped.
+func (tw *Tween) Done() bool {
+	return tw.stopped || (tw.started && tw.elapsed >= tw.ticks)
+}
+
+// step advances the tween by one tick, and reports whether it has reached the end.
+func (tw *Tween) step() bool {
+	if !tw.started {
+		tw.started = true
+		if tw.begin != nil {
+			tw.begin()
+		}
+	}
+	tw.elapsed++
+	if tw.elapsed >= tw.ticks {
+		tw.update(1)
+		return true
+	}
+	t := float32(tw.elapsed) / float32(tw.ticks)
+	if tw.ease != nil {
+		t = tw.ease(t)
+	}
+	tw.update(t)
+	return false
+}
+
+// Tweens runs a set of tweens, advancing each of them by one tick per Update.
+type Tweens struct {
+	active []*Tween
+}
+
+// Add starts running tw on the next Update, and returns it.
+func (ts *Tweens) Add(tw *Tween) *Tween {
+	ts.active = append(ts.active, tw)
+	return tw
+}
+
+// Len returns how many tweens are running.
+func (ts *Tweens) Len() int {
+	return len(ts.active)
+}
+
+// Update advances every tween by a tick. Tweens that end call their OnDone,
+// and are replaced by the tween chained after them (which starts from where
+// they ended, on the next Update). Tweens added by OnDone also start on the
+// next Update.
+func (ts *Tweens) Update() {
+	n := len(ts.active)
+	for i := 0; i < n; i++ {
+		tw := ts.active[i]
+		if tw.stopped || !tw.step() {
+			continue
+		}
+		if tw.done != nil {
+			tw.done()
+		}
+		if tw.next != nil && !tw.stopped {
+			ts.active[i] = tw.next
+		}
+	}
+
+	// drop the tweens that have ended
+	kept := ts.active[:0]
+	for _, tw := range ts.active {
+		if !tw.Done() {
+			kept = append(kept, tw)
+		}
+	}
+	for i := len(kept); i < len(ts.active); i++ {
+		ts.active[i] = nil
+	}
+	ts.active = kept
+}
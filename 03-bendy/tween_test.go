@@ -0,0 +1,165 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestEasings(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Ease      Easing
+		Overshoot bool // whether it goes past 1 on the way
+	}{
+		{"Linear", Linear, false},
+		{"EaseInQuad", EaseInQuad, false},
+		{"EaseOutQuad", EaseOutQuad, false},
+		{"EaseInOutQuad", EaseInOutQuad, false},
+		{"EaseInCubic", EaseInCubic, false},
+		{"EaseOutCubic", EaseOutCubic, false},
+		{"EaseInOutCubic", EaseInOutCubic, false},
+		{"EaseOutElastic", EaseOutElastic, true},
+		{"Spring", Spring(6, 1.5), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assertNear(t, "start", tc.Ease(0), 0)
+			assertNear(t, "end", tc.Ease(1), 1)
+
+			var peak float32
+			for i := 1; i < 100; i++ {
+				peak = max32(peak, tc.Ease(float32(i)/100))
+			}
+			if overshoot := peak > 1; overshoot != tc.Overshoot {
+				t.Errorf("expected overshoot %v, got peak of %v", tc.Overshoot, peak)
+			}
+		})
+	}
+
+	// in-out easings are symmetric around the middle
+	assertNear(t, "EaseInOutQuad middle", EaseInOutQuad(0.5), 0.5)
+	assertNear(t, "EaseInOutCubic symmetry", EaseInOutCubic(0.25), 1-EaseInOutCubic(0.75))
+}
+
+func TestTweenFloat(t *testing.T) {
+	var ts Tweens
+	v := float32(10)
+	tw := ts.Add(TweenFloat(&v, 20, 4, Linear))
+
+	// the tween starts from wherever the value is on its first tick
+	v = 0
+	expected := []float32{5, 10, 15, 20}
+	for i, e := range expected {
+		ts.Update()
+		if v != e {
+			t.Errorf("tick %d: expected %v, got %v", i, e, v)
+		}
+	}
+	if !tw.Done() || ts.Len() != 0 {
+		t.Errorf("expected the tween to be done and removed, got done %v, len %d", tw.Done(), ts.Len())
+	}
+}
+
+func TestTween_ChainsAndCallsOnDone(t *testing.T) {
+	var ts Tweens
+	var v float32
+	var done []string
+	first := TweenFloat(&v, 1, 2, Linear).OnDone(func() { done = append(done, "first") })
+	first.Then(TweenFloat(&v, -1, 2, EaseInQuad)).OnDone(func() { done = append(done, "second") })
+	ts.Add(first)
+
+	expected := []float32{0.5, 1, 0.5, -1}
+	for i, e := range expected {
+		ts.Update()
+		if v != e {
+			t.Errorf("tick %d: expected %v, got %v", i, e, v)
+		}
+	}
+	if len(done) != 2 || done[0] != "first" || done[1] != "second" {
+		t.Errorf("expected first then second to be done, got %v", done)
+	}
+	if ts.Len() != 0 {
+		t.Errorf("expected no tweens left, got %d", ts.Len())
+	}
+}
+
+func TestTween_Stop(t *testing.T) {
+	var ts Tweens
+	var v float32
+	called := false
+	tw := ts.Add(TweenFloat(&v, 1, 4, Linear).OnDone(func() { called = true }))
+	tw.Then(TweenFloat(&v, 2, 1, Linear))
+
+	ts.Update()
+	tw.Stop()
+	for i := 0; i < 4; i++ {
+		ts.Update()
+	}
+	if v != 0.25 || called || ts.Len() != 0 {
+		t.Errorf("expected v to stay at 0.25, without OnDone or the chained tween, got %v %v %d", v, called, ts.Len())
+	}
+
+	// stopping nothing is fine, so scenes can stop tweens they may not have started
+	var none *Tween
+	none.Stop()
+}
+
+func TestTweenRot(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Tween    func(xfm *Transform2D, to float32, ticks int, ease Easing) *Tween
+		From, To float32
+		Mid      float32 // halfway, with Linear
+	}{
+		{"forward", TweenRot, 0, 1, 0.5},
+		{"back", TweenRot, 1, 0, 0.5},
+		{"more than half a turn forward", TweenRot, 0.1, twoPi - 0.1, math.Pi},
+		{"more than half a turn back", TweenRot, twoPi - 0.2, 0, math.Pi - 0.1},
+		{"shortest forward", TweenRotShortest, 0, 1, 0.5},
+		{"shortest back", TweenRotShortest, 1, 0, 0.5},
+		{"shortest forward past 2π", TweenRotShortest, twoPi - 0.2, 0, twoPi - 0.1},
+		{"shortest back past 0", TweenRotShortest, 0.1, twoPi - 0.1, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			xfm := NewTransform2D()
+			xfm.SetRot(tc.From)
+			var ts Tweens
+			ts.Add(tc.Tween(&xfm, tc.To, 2, Linear))
+
+			ts.Update()
+			assertNear(t, "halfway", xfm.Rot(), tc.Mid)
+			ts.Update()
+			if xfm.Rot() != tc.To {
+				t.Errorf("expected to end exactly at %v, got %v", tc.To, xfm.Rot())
+			}
+		})
+	}
+}
+
+func TestTweenPosScaleColor(t *testing.T) {
+	var ts Tweens
+	xfm := NewTransform2D()
+	var c color.Color = color.RGBA{0, 0, 0, 0xff}
+	ts.Add(TweenPos(&xfm, Vec2D{10, 20}, 2, Linear))
+	ts.Add(TweenScale(&xfm, Vec2D{3, 3}, 2, Linear))
+	ts.Add(TweenColor(&c, color.RGBA{0xff, 0x80, 0, 0xff}, 2, EaseOutElastic))
+
+	ts.Update()
+	assertVec(t, xfm.Pos(), Vec2D{5, 10})
+	assertVec(t, xfm.Scale(), Vec2D{2, 2})
+	// the elastic ease overshoots at the halfway point, so the color is clamped to the end
+	if c != (color.RGBA{0xff, 0x80, 0, 0xff}) {
+		t.Errorf("expected the end color, got %v", c)
+	}
+
+	ts.Update()
+	assertVec(t, xfm.Pos(), Vec2D{10, 20})
+	assertVec(t, xfm.Scale(), Vec2D{3, 3})
+	if c != (color.RGBA{0xff, 0x80, 0, 0xff}) {
+		t.Errorf("expected the end color, got %v", c)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import "math"
+
+type Vec2D struct {
+	X, Y float32
+}
+
+func (v Vec2D) Add(w Vec2D) Vec2D {
+	return Vec2D{v.X + w.X, v.Y + w.Y}
+}
+
+func (v Vec2D) Sub(w Vec2D) Vec2D {
+	return Vec2D{v.X - w.X, v.Y - w.Y}
+}
+
+func (v Vec2D) Scale(s float32) Vec2D {
+	return Vec2D{v.X * s, v.Y * s}
+}
+
+func (v Vec2D) Dot(w Vec2D) float32 {
+	return v.X*w.X + v.Y*w.Y
+}
+
+// Cross returns the z component of the 3D cross product of v and w (treating
+// both as having z=0). The sign tells which side of v that w is on.
+func (v Vec2D) Cross(w Vec2D) float32 {
+	return v.X*w.Y - v.Y*w.X
+}
+
+func (v Vec2D) Length() float32 {
+	return float32(math.Hypot(float64(v.X), float64(v.Y)))
+}
+
+// LengthSq is the squared length, which is cheaper than Length when comparing distances.
+func (v Vec2D) LengthSq() float32 {
+	return v.X*v.X + v.Y*v.Y
+}
+
+// Normalize returns a vector of length 1 pointing the same way as v, or the
+// zero vector if v is the zero vector.
+func (v Vec2D) Normalize() Vec2D {
+	l := v.Length()
+	if l == 0 {
+		return Vec2D{}
+	}
+	return Vec2D{v.X / l, v.Y / l}
+}
+
+// Rotate returns v rotated around the origin by rad radians (clockwise on screen, where y points down).
+func (v Vec2D) Rotate(rad float32) Vec2D {
+	s, c := math.Sincos(float64(rad))
+	sin, cos := float32(s), float32(c)
+	return Vec2D{
+		v.X*cos - v.Y*sin,
+		v.X*sin + v.Y*cos,
+	}
+}
+
+// Lerp linearly interpolates from v (when t=0) to w (when t=1).
+func (v Vec2D) Lerp(w Vec2D, t float32) Vec2D {
+	return Vec2D{
+		v.X + (w.X-v.X)*t,
+		v.Y + (w.Y-v.Y)*t,
+	}
+}
+
+// AngleTo returns the signed angle in radians, in the range [-π,π], that v would
+// need to be rotated by to point the same way as w.
+func (v Vec2D) AngleTo(w Vec2D) float32 {
+	return float32(math.Atan2(float64(v.Cross(w)), float64(v.Dot(w))))
+}
+
+// Perp returns v rotated by 90 degrees.
+func (v Vec2D) Perp() Vec2D {
+	return Vec2D{-v.Y, v.X}
+}
+
+func (v Vec2D) Distance(w Vec2D) float32 {
+	return w.Sub(v).Length()
+}
+
+// ApproxEqual reports whether each component of v is within epsilon of the same component of w.
+func (v Vec2D) ApproxEqual(w Vec2D, epsilon float32) bool {
+	return float32(math.Abs(float64(v.X-w.X))) <= epsilon &&
+		float32(math.Abs(float64(v.Y-w.Y))) <= epsilon
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func assertVec(t *testing.T, v, w Vec2D) {
+	t.Helper()
+	if !v.ApproxEqual(w, 1e-5) {
+		t.Errorf("vector mismatch: %v != %v", v, w)
+	}
+}
+
+func TestVec2D_Arithmetic(t *testing.T) {
+	a := Vec2D{1, 2}
+	b := Vec2D{3, -4}
+	assertVec(t, a.Add(b), Vec2D{4, -2})
+	assertVec(t, a.Sub(b), Vec2D{-2, 6})
+	assertVec(t, a.Scale(3), Vec2D{3, 6})
+	assertNear(t, "dot", a.Dot(b), -5)
+	assertNear(t, "cross", a.Cross(b), -10)
+	assertNear(t, "length", b.Length(), 5)
+	assertNear(t, "length squared", b.LengthSq(), 25)
+	assertNear(t, "distance", a.Distance(b), float32(math.Sqrt(40)))
+	assertVec(t, a.Perp(), Vec2D{-2, 1})
+}
+
+func TestVec2D_Normalize(t *testing.T) {
+	assertVec(t, Vec2D{3, -4}.Normalize(), Vec2D{0.6, -0.8})
+	assertVec(t, Vec2D{}.Normalize(), Vec2D{})
+}
+
+func TestVec2D_Rotate(t *testing.T) {
+	cases := []struct {
+		Name   string
+		V      Vec2D
+		Rad    float32
+		Expect Vec2D
+	}{
+		{"zero", Vec2D{1, 2}, 0, Vec2D{1, 2}},
+		{"quarter", Vec2D{1, 0}, math.Pi / 2, Vec2D{0, 1}},
+		{"half", Vec2D{1, 2}, math.Pi, Vec2D{-1, -2}},
+		{"negative quarter", Vec2D{0, 1}, -math.Pi / 2, Vec2D{1, 0}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assertVec(t, tc.V.Rotate(tc.Rad), tc.Expect)
+			// must agree with the matrix version
+			assertVec(t, Rotation3x3(tc.Rad).MultiplyVec2D(tc.V), tc.Expect)
+		})
+	}
+}
+
+func TestVec2D_Lerp(t *testing.T) {
+	a := Vec2D{0, 10}
+	b := Vec2D{10, 20}
+	assertVec(t, a.Lerp(b, 0), a)
+	assertVec(t, a.Lerp(b, 1), b)
+	assertVec(t, a.Lerp(b, 0.25), Vec2D{2.5, 12.5})
+}
+
+func TestVec2D_AngleTo(t *testing.T) {
+	x := Vec2D{1, 0}
+	assertNear(t, "same", x.AngleTo(Vec2D{5, 0}), 0)
+	assertNear(t, "quarter", x.AngleTo(Vec2D{0, 3}), math.Pi/2)
+	assertNear(t, "negative quarter", x.AngleTo(Vec2D{0, -3}), -math.Pi/2)
+	assertNear(t, "half", x.AngleTo(Vec2D{-1, 0}), math.Pi)
+}
+
+func TestVec2D_ApproxEqual(t *testing.T) {
+	a := Vec2D{1, 1}
+	if !a.ApproxEqual(Vec2D{1.05, 0.95}, 0.1) {
+		t.Errorf("expected %v to be near %v", a, Vec2D{1.05, 0.95})
+	}
+	if a.ApproxEqual(Vec2D{1.2, 1}, 0.1) {
+		t.Errorf("expected %v to not be near %v", a, Vec2D{1.2, 1})
+	}
+}